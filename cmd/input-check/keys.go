@@ -1,123 +0,0 @@
-package main
-
-import "github.com/unxed/vtinput"
-
-var vkNames = map[uint16]string{
-	vtinput.VK_LBUTTON:    "LButton",
-	vtinput.VK_RBUTTON:    "RButton",
-	vtinput.VK_CANCEL:     "Cancel",
-	vtinput.VK_MBUTTON:    "MButton",
-	vtinput.VK_BACK:       "Backspace",
-	vtinput.VK_TAB:        "Tab",
-	vtinput.VK_CLEAR:      "Clear",
-	vtinput.VK_RETURN:     "Enter",
-	vtinput.VK_SHIFT:      "Shift",
-	vtinput.VK_CONTROL:    "Ctrl",
-	vtinput.VK_MENU:       "Alt",
-	vtinput.VK_PAUSE:      "Pause",
-	vtinput.VK_CAPITAL:    "CapsL",
-	vtinput.VK_ESCAPE:     "Esc",
-	vtinput.VK_SPACE:      "Space",
-	vtinput.VK_PRIOR:      "PgUp",
-	vtinput.VK_NEXT:       "PgDn",
-	vtinput.VK_END:        "End",
-	vtinput.VK_HOME:       "Home",
-	vtinput.VK_LEFT:       "Left",
-	vtinput.VK_UP:         "Up",
-	vtinput.VK_RIGHT:      "Right",
-	vtinput.VK_DOWN:       "Down",
-	vtinput.VK_SELECT:     "Select",
-	vtinput.VK_PRINT:      "Print",
-	vtinput.VK_EXECUTE:    "Execute",
-	vtinput.VK_SNAPSHOT:   "PrtScn",
-	vtinput.VK_INSERT:     "Insert",
-	vtinput.VK_DELETE:     "Delete",
-	vtinput.VK_HELP:       "Help",
-	vtinput.VK_0:          "0",
-	vtinput.VK_1:          "1",
-	vtinput.VK_2:          "2",
-	vtinput.VK_3:          "3",
-	vtinput.VK_4:          "4",
-	vtinput.VK_5:          "5",
-	vtinput.VK_6:          "6",
-	vtinput.VK_7:          "7",
-	vtinput.VK_8:          "8",
-	vtinput.VK_9:          "9",
-	vtinput.VK_A:          "A",
-	vtinput.VK_B:          "B",
-	vtinput.VK_C:          "C",
-	vtinput.VK_D:          "D",
-	vtinput.VK_E:          "E",
-	vtinput.VK_F:          "F",
-	vtinput.VK_G:          "G",
-	vtinput.VK_H:          "H",
-	vtinput.VK_I:          "I",
-	vtinput.VK_J:          "J",
-	vtinput.VK_K:          "K",
-	vtinput.VK_L:          "L",
-	vtinput.VK_M:          "M",
-	vtinput.VK_N:          "N",
-	vtinput.VK_O:          "O",
-	vtinput.VK_P:          "P",
-	vtinput.VK_Q:          "Q",
-	vtinput.VK_R:          "R",
-	vtinput.VK_S:          "S",
-	vtinput.VK_T:          "T",
-	vtinput.VK_U:          "U",
-	vtinput.VK_V:          "V",
-	vtinput.VK_W:          "W",
-	vtinput.VK_X:          "X",
-	vtinput.VK_Y:          "Y",
-	vtinput.VK_Z:          "Z",
-	vtinput.VK_LWIN:       "LWin",
-	vtinput.VK_RWIN:       "RWin",
-	vtinput.VK_APPS:       "Apps",
-	vtinput.VK_NUMPAD0:    "Num0",
-	vtinput.VK_NUMPAD1:    "Num1",
-	vtinput.VK_NUMPAD2:    "Num2",
-	vtinput.VK_NUMPAD3:    "Num3",
-	vtinput.VK_NUMPAD4:    "Num4",
-	vtinput.VK_NUMPAD5:    "Num5",
-	vtinput.VK_NUMPAD6:    "Num6",
-	vtinput.VK_NUMPAD7:    "Num7",
-	vtinput.VK_NUMPAD8:    "Num8",
-	vtinput.VK_NUMPAD9:    "Num9",
-	vtinput.VK_MULTIPLY:   "Num*",
-	vtinput.VK_ADD:        "Num+",
-	vtinput.VK_SEPARATOR:  "Num,",
-	vtinput.VK_SUBTRACT:   "Num-",
-	vtinput.VK_DECIMAL:    "Num.",
-	vtinput.VK_DIVIDE:     "Num/",
-	vtinput.VK_F1:         "F1",
-	vtinput.VK_F2:         "F2",
-	vtinput.VK_F3:         "F3",
-	vtinput.VK_F4:         "F4",
-	vtinput.VK_F5:         "F5",
-	vtinput.VK_F6:         "F6",
-	vtinput.VK_F7:         "F7",
-	vtinput.VK_F8:         "F8",
-	vtinput.VK_F9:         "F9",
-	vtinput.VK_F10:        "F10",
-	vtinput.VK_F11:        "F11",
-	vtinput.VK_F12:        "F12",
-	vtinput.VK_NUMLOCK:    "NumL",
-	vtinput.VK_SCROLL:     "ScrL",
-	vtinput.VK_LSHIFT:     "LShift",
-	vtinput.VK_RSHIFT:     "RShift",
-	vtinput.VK_LCONTROL:   "LCtrl",
-	vtinput.VK_RCONTROL:   "RCtrl",
-	vtinput.VK_LMENU:      "LAlt",
-	vtinput.VK_RMENU:      "RAlt",
-	vtinput.VK_OEM_1:      ";:",
-	vtinput.VK_OEM_PLUS:   "+",
-	vtinput.VK_OEM_COMMA:  ",",
-	vtinput.VK_OEM_MINUS:  "-",
-	vtinput.VK_OEM_PERIOD: ".",
-	vtinput.VK_OEM_2:      "/?",
-	vtinput.VK_OEM_3:      "`~",
-	vtinput.VK_OEM_4:      "[{",
-	vtinput.VK_OEM_5:      "\\|",
-	vtinput.VK_OEM_6:      "]}",
-	vtinput.VK_OEM_7:      "'\"",
-	vtinput.VK_OEM_102:    "< > |",
-}
\ No newline at end of file
@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"sync"
 	"time"
@@ -14,7 +13,6 @@ import (
 // activeKey holds state for a pressed key
 type activeKey struct {
 	pressedAt time.Time
-	isLegacy  bool
 	isDown    bool
 }
 
@@ -24,6 +22,7 @@ var (
 	logLines    []string
 	logLimit    = 10
 	currentMods uint32
+	keyboard    vtinput.KeyboardState
 )
 
 const (
@@ -49,6 +48,9 @@ var keyRows = [][]uint16{
 func main() {
 	useWin32 := flag.Bool("win32", true, "Enable Win32 Input Mode")
 	useKitty := flag.Bool("kitty", true, "Enable Kitty Keyboard Protocol")
+	useKittyText := flag.Bool("kittytext", false, "Request associated text from Kitty Keyboard Protocol")
+	useMOK := flag.Bool("mok", false, "Enable xterm modifyOtherKeys")
+	useKeypad := flag.Bool("keypad", false, "Enable application keypad mode (DECKPAM)")
 	useMouse := flag.Bool("mouse", true, "Enable Mouse Support")
 	useExt := flag.Bool("ext", true, "Enable Focus and Bracketed Paste")
 	flag.Parse()
@@ -56,6 +58,9 @@ func main() {
 	var mask vtinput.Protocol
 	if *useWin32 { mask |= vtinput.Win32InputMode }
 	if *useKitty { mask |= vtinput.KittyKeyboard }
+	if *useKittyText { mask |= vtinput.KittyAssociatedText }
+	if *useMOK { mask |= vtinput.ModifyOtherKeys }
+	if *useKeypad { mask |= vtinput.KeypadApplicationMode }
 	if *useMouse { mask |= vtinput.MouseSupport }
 	if *useExt { mask |= vtinput.FocusAndPaste }
 
@@ -70,31 +75,25 @@ func main() {
 	fmt.Print("\033[2J\033[?25l")
 	defer fmt.Print("\033[?25h") // Show cursor on exit
 
-	reader := vtinput.NewReader(os.Stdin)
+	// Legacy protocols have no key releases; let the reader synthesize them.
+	reader := vtinput.NewReaderWithOptions(os.Stdin, vtinput.ReaderOptions{SynthesizeKeyUp: true})
 	ticker := time.NewTicker(50 * time.Millisecond)
 	defer ticker.Stop()
 
 	// Initial draw
 	drawUI()
 
-	// Event channel to bridge reader and select loop
-	eventChan := make(chan *vtinput.InputEvent)
-	go func() {
-		for {
-			e, err := reader.ReadEvent()
-			if err != nil {
-				if err != io.EOF {
-					// In a real app we might handle error, here just exit loop
-				}
-				return
-			}
-			eventChan <- e
-		}
-	}()
+	events := reader.Events()
 
 	for {
 		select {
-		case e := <-eventChan:
+		case e, ok := <-events:
+			if !ok {
+				if err := reader.Err(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\r\n", err)
+				}
+				return
+			}
 			handleEvent(e)
 			if isExitEvent(e) {
 				return
@@ -106,12 +105,8 @@ func main() {
 				changed := false
 				now := time.Now()
 				for k, v := range pressedKeys {
-					// Remove legacy keys after 150ms timeout
-					if v.isLegacy && now.Sub(v.pressedAt) > 150*time.Millisecond {
-						delete(pressedKeys, k)
-						changed = true
-					// Remove modern keys only if released AND 100ms passed (to make fast typing visible)
-					} else if !v.isLegacy && !v.isDown && now.Sub(v.pressedAt) > 100*time.Millisecond {
+					// Remove keys only if released AND 100ms passed (to make fast typing visible)
+					if !v.isDown && now.Sub(v.pressedAt) > 100*time.Millisecond {
 						delete(pressedKeys, k)
 						changed = true
 					}
@@ -128,36 +123,9 @@ func handleEvent(e *vtinput.InputEvent) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	// Terminal emulators often send stale modifier states for lock keys on key release.
-	// We predict the new lock state on key down (inverting the pre-press state),
-	// and ignore the event's lock bits for these keys to prevent visual sticking.
-	if e.Type == vtinput.KeyEventType && (e.VirtualKeyCode == vtinput.VK_CAPITAL || e.VirtualKeyCode == vtinput.VK_NUMLOCK || e.VirtualKeyCode == vtinput.VK_SCROLL) {
-		if e.KeyDown {
-			if e.VirtualKeyCode == vtinput.VK_CAPITAL {
-				if (e.ControlKeyState & vtinput.CapsLockOn) == 0 {
-					currentMods |= vtinput.CapsLockOn
-				} else {
-					currentMods &= ^uint32(vtinput.CapsLockOn)
-				}
-			} else if e.VirtualKeyCode == vtinput.VK_NUMLOCK {
-				if (e.ControlKeyState & vtinput.NumLockOn) == 0 {
-					currentMods |= vtinput.NumLockOn
-				} else {
-					currentMods &= ^uint32(vtinput.NumLockOn)
-				}
-			} else if e.VirtualKeyCode == vtinput.VK_SCROLL {
-				if (e.ControlKeyState & vtinput.ScrollLockOn) == 0 {
-					currentMods |= vtinput.ScrollLockOn
-				} else {
-					currentMods &= ^uint32(vtinput.ScrollLockOn)
-				}
-			}
-		}
-		lockMask := uint32(vtinput.CapsLockOn | vtinput.NumLockOn | vtinput.ScrollLockOn)
-		currentMods = (currentMods & lockMask) | (e.ControlKeyState & ^lockMask)
-	} else {
-		currentMods = e.ControlKeyState
-	}
+	// KeyboardState also works around stale lock bits sent with lock keys.
+	keyboard.Update(e)
+	currentMods = keyboard.Modifiers()
 
 	// Log message
 	msg := fmt.Sprintf("Event: %s", e)
@@ -180,7 +148,6 @@ func handleEvent(e *vtinput.InputEvent) {
 		if e.KeyDown {
 			pressedKeys[vk] = activeKey{
 				pressedAt: time.Now(),
-				isLegacy:  e.IsLegacy,
 				isDown:    true,
 			}
 		} else {
@@ -218,10 +185,7 @@ func drawUI() {
 				continue
 			}
 
-			name, ok := vkNames[vk]
-			if !ok {
-				name = fmt.Sprintf("0x%X", vk)
-			}
+			name := vtinput.KeyName(vk)
 
 			// Check if pressed directly
 			isPressed := false
@@ -13,6 +13,14 @@ const (
 	ScrollLockOn     = 0x0040
 	CapsLockOn       = 0x0080
 	EnhancedKey      = 0x0100
+
+	// Extensions beyond Win32, reported by the kitty protocol and modified CSI keys.
+	// Modifier bit 8 means Super in kitty but Meta in xterm, and a sequence does
+	// not tell them apart: it is always SuperPressed, so xterm's Meta shows up
+	// as Super and MetaPressed only comes from kitty's bit 32.
+	SuperPressed = 0x0200
+	HyperPressed = 0x0400
+	MetaPressed  = 0x0800
 )
 
 // Mouse Button States (dwButtonState)
@@ -22,6 +30,10 @@ const (
 	FromLeft2ndButtonPressed = 0x0004
 	FromLeft3rdButtonPressed = 0x0008
 	FromLeft4thButtonPressed = 0x0010
+
+	// Extensions beyond Win32 for xterm buttons 10 and 11.
+	FromLeft5thButtonPressed = 0x0020
+	FromLeft6thButtonPressed = 0x0040
 )
 
 // Mouse Event Flags (dwEventFlags)
@@ -40,6 +52,8 @@ const (
 	MouseEventType EventType = 0x0002
 	FocusEventType EventType = 0x0010
 	PasteEventType EventType = 0x0020
+
+	CursorPositionEventType EventType = 0x0040
 )
 
 // InputEvent is a generic container for any event (Key, Mouse, Focus).
@@ -54,6 +68,13 @@ type InputEvent struct {
 	UnshiftedChar   rune
 	KeyDown         bool
 	RepeatCount     uint16
+	// Text is the text produced by the key as reported by the terminal, which
+	// may be several codepoints (composed characters, IME input, emoji
+	// sequences). Only set by kitty with KittyAssociatedText enabled.
+	Text string
+	// IsRepeat marks a key-down generated by auto-repeat while the key is held.
+	// Only protocols that report it (kitty) set this flag.
+	IsRepeat bool
 
 	// Mouse Event Data (Future proofing)
 	MouseX          uint16
@@ -61,20 +82,50 @@ type InputEvent struct {
 	ButtonState     uint32
 	MouseEventFlags uint32
 	WheelDirection  int // 1 (forward/right), -1 (backward/left)
+	// Pointer position in pixels, set only in SGR-Pixels mode
+	// (ReaderOptions.MousePixels). MouseX/MouseY then hold the cell under
+	// the pointer if the cell size is known, see PixelsToCells.
+	MousePixelX uint16
+	MousePixelY uint16
+	// ClickCount numbers a button press within a series of quick clicks
+	// (2 for a double click, 3 for a triple one). Only set on presses and
+	// only with ReaderOptions.DetectClicks.
+	ClickCount uint16
 
 	// Focus Event Data
 	SetFocus bool
 
 	// Paste Event Data
 	PasteStart bool
+	// PasteText holds the verbatim pasted text when the Reader collects
+	// bracketed paste (ReaderOptions.CollectPaste). Such events replace the
+	// start/end markers, have PasteStart unset and PasteCollected set; the
+	// text of an empty paste is "".
+	PasteText string
+	// PasteCollected tells a collected paste apart from an end marker.
+	PasteCollected bool
+	// PastePartial is set on streamed paste events that are followed by more
+	// text from the same paste (ReaderOptions.StreamPaste).
+	PastePartial bool
+	// PasteTruncated is set when text beyond ReaderOptions.MaxPasteSize was
+	// discarded from the paste.
+	PasteTruncated bool
+
+	// Cursor Position Report Data (0-based, like mouse coordinates)
+	CursorRow uint16
+	CursorCol uint16
 
 	// Shared
 	ControlKeyState uint32
 
 	// IsLegacy indicates that this event comes from a protocol that does not support
 	// explicit KeyUp events (e.g. standard ANSI). The application may need to
-	// simulate KeyUp after a timeout.
+	// simulate KeyUp after a timeout, or let the Reader do it
+	// (ReaderOptions.SynthesizeKeyUp).
 	IsLegacy bool
+	// IsSynthetic marks a KeyUp generated by the Reader rather than reported
+	// by the terminal.
+	IsSynthetic bool
 }
 
 // String implements the Stringer interface for easy debugging.
@@ -89,6 +140,12 @@ func (e InputEvent) String() string {
 		if e.KeyDown {
 			state = "DOWN"
 		}
+		if e.IsRepeat {
+			state = "REPEAT"
+		}
+		if e.IsSynthetic {
+			state = "UP*"
+		}
 		charStr := ""
 		if e.Char > 0 {
 			if e.Char < 32 {
@@ -107,8 +164,13 @@ func (e InputEvent) String() string {
 			}
 		}
 
-		return fmt.Sprintf("Key{VK:0x%X Scan:0x%X%s%s %s Mods:0x%X}%s",
-			e.VirtualKeyCode, e.VirtualScanCode, charStr, baseStr, state, e.ControlKeyState, legacyStr)
+		textStr := ""
+		if e.Text != "" {
+			textStr = fmt.Sprintf(" Text:%q", e.Text)
+		}
+
+		return fmt.Sprintf("Key{VK:0x%X Scan:0x%X%s%s%s %s Mods:0x%X}%s",
+			e.VirtualKeyCode, e.VirtualScanCode, charStr, baseStr, textStr, state, e.ControlKeyState, legacyStr)
 	}
 
 	if e.Type == MouseEventType {
@@ -120,6 +182,14 @@ func (e InputEvent) String() string {
 			btn = "Middle"
 		case RightmostButtonPressed:
 			btn = "Right"
+		case FromLeft3rdButtonPressed:
+			btn = "Back"
+		case FromLeft4thButtonPressed:
+			btn = "Forward"
+		case FromLeft5thButtonPressed:
+			btn = "Button10"
+		case FromLeft6thButtonPressed:
+			btn = "Button11"
 		}
 
 		action := "UP"
@@ -131,15 +201,29 @@ func (e InputEvent) String() string {
 		}
 
 		wheel := ""
-		if e.WheelDirection > 0 {
-			wheel = " WHEEL_UP"
+		if (e.MouseEventFlags & MouseHWheeled) != 0 {
+			if e.WheelDirection > 0 {
+				wheel = " WHEEL_RIGHT"
+			}
+			if e.WheelDirection < 0 {
+				wheel = " WHEEL_LEFT"
+			}
+		} else {
+			if e.WheelDirection > 0 {
+				wheel = " WHEEL_UP"
+			}
+			if e.WheelDirection < 0 {
+				wheel = " WHEEL_DOWN"
+			}
 		}
-		if e.WheelDirection < 0 {
-			wheel = " WHEEL_DOWN"
+
+		clicks := ""
+		if e.ClickCount > 1 {
+			clicks = fmt.Sprintf(" CLICKS:%d", e.ClickCount)
 		}
 
-		return fmt.Sprintf("Mouse{Pos:%d,%d Btn:%s %s%s Mods:0x%X}%s",
-			e.MouseX, e.MouseY, btn, action, wheel, e.ControlKeyState, legacyStr)
+		return fmt.Sprintf("Mouse{Pos:%d,%d Btn:%s %s%s%s Mods:0x%X}%s",
+			e.MouseX, e.MouseY, btn, action, wheel, clicks, e.ControlKeyState, legacyStr)
 	}
 
 	if e.Type == FocusEventType {
@@ -151,6 +235,16 @@ func (e InputEvent) String() string {
 	}
 
 	if e.Type == PasteEventType {
+		if e.PasteCollected {
+			more := ""
+			if e.PastePartial {
+				more = " MORE"
+			}
+			if e.PasteTruncated {
+				more = " TRUNCATED"
+			}
+			return fmt.Sprintf("Paste{TEXT:%d bytes%s}", len(e.PasteText), more)
+		}
 		state := "END"
 		if e.PasteStart {
 			state = "START"
@@ -158,5 +252,9 @@ func (e InputEvent) String() string {
 		return fmt.Sprintf("Paste{%s}", state)
 	}
 
+	if e.Type == CursorPositionEventType {
+		return fmt.Sprintf("CursorPosition{Row:%d Col:%d}", e.CursorRow, e.CursorCol)
+	}
+
 	return fmt.Sprintf("Event{Type:%d Mods:0x%X}%s", e.Type, e.ControlKeyState, legacyStr)
 }
@@ -19,6 +19,11 @@ func TestInputEvent_String(t *testing.T) {
 		t.Errorf("Unexpected string output for KeyEvent: %s", strKey)
 	}
 
+	eRepeat := InputEvent{Type: KeyEventType, VirtualKeyCode: VK_A, KeyDown: true, IsRepeat: true}
+	if !strings.Contains(eRepeat.String(), " REPEAT ") {
+		t.Errorf("Unexpected string output for repeated KeyEvent: %s", eRepeat.String())
+	}
+
 	// Test Mouse Event formatting
 	eMouse := InputEvent{
 		Type:        MouseEventType,
@@ -0,0 +1,198 @@
+package vtinput
+
+// lockKeyState covers the ControlKeyState bits of toggled lock keys.
+const lockKeyState = CapsLockOn | NumLockOn | ScrollLockOn
+
+// KeyboardState follows a stream of events to tell which keys are held,
+// which modifiers are down and which lock keys are on. The zero value is
+// ready to use; feed it every event with Update.
+//
+// Legacy protocols report no key releases, so their keys stay held until
+// another event says otherwise. Read them with ReaderOptions.SynthesizeKeyUp
+// to have legacy keys released as well.
+type KeyboardState struct {
+	held  map[uint16]bool
+	mods  uint32 // ControlKeyState without lock bits
+	locks uint32 // lock bits of ControlKeyState
+}
+
+// Update folds an event into the state. Events other than keys and mouse
+// reports are ignored.
+func (s *KeyboardState) Update(e *InputEvent) {
+	switch e.Type {
+	case KeyEventType:
+	case MouseEventType:
+		s.mods = e.ControlKeyState &^ (lockKeyState | EnhancedKey)
+		return
+	default:
+		return
+	}
+	if s.held == nil {
+		s.held = make(map[uint16]bool)
+	}
+
+	vk := s.sidedKey(e)
+	if e.KeyDown {
+		s.held[vk] = true
+	} else {
+		delete(s.held, vk)
+	}
+
+	s.mods = e.ControlKeyState &^ (lockKeyState | EnhancedKey)
+	if bit := modifierBit(vk); bit != 0 {
+		// Terminals disagree on whether a modifier's own event includes
+		// its bit, so derive it from the key itself.
+		if e.KeyDown || s.sharesModifier(vk) {
+			s.mods |= bit
+		} else {
+			s.mods &^= bit
+		}
+	}
+
+	if lock := lockBit(vk); lock != 0 {
+		// Terminals often send stale lock bits around lock key events, so
+		// predict the new state on key down by inverting the pre-press state,
+		// and otherwise ignore lock bits of these events.
+		if e.KeyDown && !e.IsRepeat {
+			if (e.ControlKeyState & lock) == 0 {
+				s.locks |= lock
+			} else {
+				s.locks &^= lock
+			}
+		}
+	} else if !e.IsLegacy {
+		// Legacy sequences never carry lock bits.
+		s.locks = e.ControlKeyState & lockKeyState
+	}
+}
+
+// IsDown reports whether the key vk is held. The generic VK_SHIFT, VK_CONTROL
+// and VK_MENU match either side; VK_LSHIFT, VK_RCONTROL etc. match one side.
+func (s *KeyboardState) IsDown(vk uint16) bool {
+	switch vk {
+	case VK_SHIFT:
+		return s.held[VK_SHIFT] || s.held[VK_LSHIFT] || s.held[VK_RSHIFT]
+	case VK_CONTROL:
+		return s.held[VK_LCONTROL] || s.held[VK_RCONTROL]
+	case VK_MENU:
+		return s.held[VK_LMENU] || s.held[VK_RMENU]
+	}
+	return s.held[vk]
+}
+
+// Held returns the virtual key codes of all held keys, in no particular order.
+// Modifier keys are reported by side (VK_LSHIFT, VK_RCONTROL, ...) when known.
+func (s *KeyboardState) Held() []uint16 {
+	keys := make([]uint16, 0, len(s.held))
+	for vk := range s.held {
+		keys = append(keys, vk)
+	}
+	return keys
+}
+
+// Modifiers returns the modifiers that are down and the locks that are on,
+// as ControlKeyState flags.
+func (s *KeyboardState) Modifiers() uint32 {
+	return s.mods | s.locks
+}
+
+// Locks returns the CapsLockOn, NumLockOn and ScrollLockOn flags that are on.
+func (s *KeyboardState) Locks() uint32 {
+	return s.locks
+}
+
+// Reset forgets all held keys and modifiers, e.g. after focus loss, when
+// releases may have gone to another window. Lock states are kept.
+func (s *KeyboardState) Reset() {
+	clear(s.held)
+	s.mods = 0
+}
+
+// sidedKey resolves the generic modifier codes of an event to the left or
+// right key, like sidedModifierKey, but releases whichever side is held when
+// the event does not tell.
+func (s *KeyboardState) sidedKey(e *InputEvent) uint16 {
+	vk := sidedModifierKey(e)
+	if e.KeyDown || (e.ControlKeyState&EnhancedKey) != 0 {
+		return vk
+	}
+	// Some releases lack EnhancedKey; release the side that is actually held.
+	switch {
+	case vk == VK_LCONTROL && !s.held[vk] && s.held[VK_RCONTROL]:
+		return VK_RCONTROL
+	case vk == VK_LMENU && !s.held[vk] && s.held[VK_RMENU]:
+		return VK_RMENU
+	}
+	return vk
+}
+
+// sidedModifierKey resolves the generic modifier codes of an event to the
+// left or right key, using the scan code for Shift and EnhancedKey for Ctrl
+// and Alt. Shift stays generic if the scan code does not tell.
+func sidedModifierKey(e *InputEvent) uint16 {
+	isRight := (e.ControlKeyState & EnhancedKey) != 0
+	switch e.VirtualKeyCode {
+	case VK_SHIFT:
+		switch e.VirtualScanCode {
+		case ScanCodeLeftShift:
+			return VK_LSHIFT
+		case ScanCodeRightShift:
+			return VK_RSHIFT
+		}
+	case VK_CONTROL:
+		if isRight {
+			return VK_RCONTROL
+		}
+		return VK_LCONTROL
+	case VK_MENU:
+		if isRight {
+			return VK_RMENU
+		}
+		return VK_LMENU
+	}
+	return e.VirtualKeyCode
+}
+
+// sharesModifier reports whether another held key still sets the modifier
+// bit of the sided key vk.
+func (s *KeyboardState) sharesModifier(vk uint16) bool {
+	switch vk {
+	case VK_SHIFT, VK_LSHIFT, VK_RSHIFT:
+		return s.IsDown(VK_SHIFT)
+	case VK_LWIN, VK_RWIN:
+		return s.held[VK_LWIN] || s.held[VK_RWIN]
+	}
+	return false
+}
+
+// modifierBit maps a sided modifier key to its ControlKeyState flag.
+func modifierBit(vk uint16) uint32 {
+	switch vk {
+	case VK_SHIFT, VK_LSHIFT, VK_RSHIFT:
+		return ShiftPressed
+	case VK_LCONTROL:
+		return LeftCtrlPressed
+	case VK_RCONTROL:
+		return RightCtrlPressed
+	case VK_LMENU:
+		return LeftAltPressed
+	case VK_RMENU:
+		return RightAltPressed
+	case VK_LWIN, VK_RWIN:
+		return SuperPressed
+	}
+	return 0
+}
+
+// lockBit maps a lock key to its ControlKeyState flag.
+func lockBit(vk uint16) uint32 {
+	switch vk {
+	case VK_CAPITAL:
+		return CapsLockOn
+	case VK_NUMLOCK:
+		return NumLockOn
+	case VK_SCROLL:
+		return ScrollLockOn
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package vtinput
+
+import "testing"
+
+func TestKeyboardState_Modifiers(t *testing.T) {
+	var s KeyboardState
+	key := func(vk, scan uint16, down bool, state uint32) *InputEvent {
+		return &InputEvent{Type: KeyEventType, VirtualKeyCode: vk, VirtualScanCode: scan, KeyDown: down, ControlKeyState: state}
+	}
+
+	s.Update(key(VK_SHIFT, ScanCodeRightShift, true, ShiftPressed))
+	if !s.IsDown(VK_RSHIFT) || s.IsDown(VK_LSHIFT) || !s.IsDown(VK_SHIFT) || s.Modifiers() != ShiftPressed {
+		t.Fatalf("Expected right Shift held, got %v mods 0x%X", s.Held(), s.Modifiers())
+	}
+
+	// Right Ctrl press is marked EnhancedKey, its kitty release is not.
+	s.Update(key(VK_CONTROL, 0, true, ShiftPressed|RightCtrlPressed|EnhancedKey))
+	if !s.IsDown(VK_RCONTROL) || s.Modifiers() != ShiftPressed|RightCtrlPressed {
+		t.Fatalf("Expected right Ctrl held, got %v mods 0x%X", s.Held(), s.Modifiers())
+	}
+	s.Update(key(VK_CONTROL, 0, false, ShiftPressed|RightCtrlPressed))
+	if s.IsDown(VK_CONTROL) || s.Modifiers() != ShiftPressed {
+		t.Fatalf("Expected right Ctrl released, got %v mods 0x%X", s.Held(), s.Modifiers())
+	}
+
+	s.Update(key(VK_SHIFT, ScanCodeRightShift, false, ShiftPressed))
+	if len(s.Held()) != 0 || s.Modifiers() != 0 {
+		t.Errorf("Expected nothing held, got %v mods 0x%X", s.Held(), s.Modifiers())
+	}
+}
+
+func TestKeyboardState_Locks(t *testing.T) {
+	var s KeyboardState
+
+	// The terminal reports the pre-press lock state on both events.
+	s.Update(&InputEvent{Type: KeyEventType, VirtualKeyCode: VK_CAPITAL, KeyDown: true})
+	s.Update(&InputEvent{Type: KeyEventType, VirtualKeyCode: VK_CAPITAL})
+	if s.Locks() != CapsLockOn {
+		t.Fatalf("Expected CapsLock on, got 0x%X", s.Locks())
+	}
+
+	// Legacy keys carry no lock bits and must not clear them.
+	s.Update(&InputEvent{Type: KeyEventType, Char: 'a', KeyDown: true, IsLegacy: true})
+	if s.Locks() != CapsLockOn {
+		t.Fatalf("Expected CapsLock kept after legacy key, got 0x%X", s.Locks())
+	}
+
+	s.Update(&InputEvent{Type: KeyEventType, VirtualKeyCode: VK_A, KeyDown: true, ControlKeyState: NumLockOn})
+	if s.Locks() != NumLockOn || s.Modifiers() != NumLockOn {
+		t.Errorf("Expected locks from a modern key event, got 0x%X", s.Locks())
+	}
+}
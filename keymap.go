@@ -0,0 +1,259 @@
+package vtinput
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultSequenceTimeout = time.Second
+
+// chordLeader marks the "Leader" placeholder in a bound sequence, which
+// matches whatever Keymap.SetLeader chose.
+const chordLeader = 0x40000
+
+// KeyHandler is called with the key event that completed a bound sequence.
+type KeyHandler func(e *InputEvent)
+
+type keyBinding struct {
+	seq     []KeyChord
+	handler KeyHandler
+}
+
+// KeyLayer is a set of bindings that a Keymap consults as a whole, such as
+// the global keys of an application, those of a panel or of a dialog.
+type KeyLayer struct {
+	bindings []keyBinding
+}
+
+// Bind binds a sequence of chords separated by spaces, e.g. "F2" or
+// "Ctrl+X Ctrl+S", to handler, replacing an existing binding of the same
+// sequence. The word "Leader" stands for the Keymap's leader chord.
+func (l *KeyLayer) Bind(sequence string, handler KeyHandler) error {
+	seq, err := parseSequence(sequence)
+	if err != nil {
+		return err
+	}
+	l.Unbind(sequence)
+	l.bindings = append(l.bindings, keyBinding{seq: seq, handler: handler})
+	return nil
+}
+
+// Unbind removes the binding of a sequence, if any.
+func (l *KeyLayer) Unbind(sequence string) {
+	seq, err := parseSequence(sequence)
+	if err != nil {
+		return
+	}
+	for i, b := range l.bindings {
+		if sameSequence(b.seq, seq) {
+			l.bindings = append(l.bindings[:i], l.bindings[i+1:]...)
+			return
+		}
+	}
+}
+
+func parseSequence(sequence string) ([]KeyChord, error) {
+	fields := strings.Fields(sequence)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: empty sequence", ErrInvalidKey)
+	}
+	seq := make([]KeyChord, len(fields))
+	for i, f := range fields {
+		if strings.EqualFold(f, "Leader") {
+			seq[i] = KeyChord{mods: chordLeader}
+			continue
+		}
+		chord, err := ParseKey(f)
+		if err != nil {
+			return nil, err
+		}
+		seq[i] = chord
+	}
+	return seq, nil
+}
+
+func sameSequence(a, b []KeyChord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Keymap dispatches key events to handlers bound in a stack of layers. Layers
+// pushed later take priority: the topmost layer with a binding that matches
+// the keys typed so far handles them, shadowing the layers below.
+//
+// Lock keys never affect matching, and modifier key presses do not interrupt
+// a sequence. If a binding is a prefix of a longer one in the same layer, it
+// runs once the sequence times out (see Expire) or is not continued.
+//
+// A Keymap must be used from a single goroutine.
+type Keymap struct {
+	// Timeout is how long a partially typed sequence waits for its next key.
+	// Zero means 1s.
+	Timeout time.Duration
+
+	layers []*KeyLayer
+	leader KeyChord
+
+	pending   []*InputEvent
+	pendingAt time.Time
+	fallback  *keyBinding // complete binding shadowed by a longer pending one
+}
+
+// Push puts a layer on top of the stack.
+func (m *Keymap) Push(l *KeyLayer) {
+	m.layers = append(m.layers, l)
+}
+
+// Remove takes a layer off the stack and abandons a partial sequence.
+func (m *Keymap) Remove(l *KeyLayer) {
+	for i := len(m.layers) - 1; i >= 0; i-- {
+		if m.layers[i] == l {
+			m.layers = append(m.layers[:i], m.layers[i+1:]...)
+			break
+		}
+	}
+	m.reset()
+}
+
+// SetLeader chooses the chord that "Leader" stands for in bound sequences.
+func (m *Keymap) SetLeader(chord string) error {
+	leader, err := ParseKey(chord)
+	if err != nil {
+		return err
+	}
+	m.leader = leader
+	return nil
+}
+
+// Pending reports whether a partial sequence is waiting for more keys.
+func (m *Keymap) Pending() bool {
+	return len(m.pending) > 0
+}
+
+// Deadline returns when the partial sequence times out, so that an event
+// loop can call Expire then. It returns false if no sequence is pending.
+func (m *Keymap) Deadline() (time.Time, bool) {
+	if len(m.pending) == 0 {
+		return time.Time{}, false
+	}
+	return m.pendingAt.Add(m.timeout()), true
+}
+
+// Expire abandons a partial sequence whose time is up, running the shorter
+// binding it shadowed, if any. It reports whether a handler ran.
+func (m *Keymap) Expire() bool {
+	return m.expire(time.Now())
+}
+
+// Dispatch feeds a key event to the keymap. It reports whether the event was
+// consumed, either by a handler or as part of a pending sequence. Key-up
+// events and other event types are ignored.
+func (m *Keymap) Dispatch(e *InputEvent) bool {
+	return m.dispatch(e, time.Now())
+}
+
+func (m *Keymap) dispatch(e *InputEvent, now time.Time) bool {
+	if e.Type != KeyEventType || !e.KeyDown {
+		return false
+	}
+	m.expire(now)
+
+	vk := sidedModifierKey(e)
+	passive := modifierBit(vk) != 0 || lockBit(vk) != 0 || vk == VK_SHIFT
+
+	seq := append(m.pending[:len(m.pending):len(m.pending)], e)
+	for i := len(m.layers) - 1; i >= 0; i-- {
+		complete, partial := m.lookup(m.layers[i], seq)
+		if partial {
+			m.pending, m.pendingAt, m.fallback = seq, now, complete
+			return true
+		}
+		if complete != nil {
+			m.reset()
+			complete.handler(e)
+			return true
+		}
+	}
+
+	if passive {
+		// Pressing Ctrl on the way to Ctrl+S must not break a sequence.
+		return len(m.pending) > 0
+	}
+	if len(m.pending) > 0 {
+		// The sequence was not continued; start over from this key.
+		ran := m.runFallback()
+		return m.dispatch(e, now) || ran
+	}
+	return false
+}
+
+// lookup finds the binding of layer that seq completes and whether some
+// binding continues beyond seq.
+func (m *Keymap) lookup(layer *KeyLayer, seq []*InputEvent) (complete *keyBinding, partial bool) {
+	for i := range layer.bindings {
+		b := &layer.bindings[i]
+		if len(b.seq) < len(seq) || !m.matchPrefix(b.seq, seq) {
+			continue
+		}
+		if len(b.seq) == len(seq) {
+			complete = b
+		} else {
+			partial = true
+		}
+	}
+	return complete, partial
+}
+
+func (m *Keymap) matchPrefix(chords []KeyChord, seq []*InputEvent) bool {
+	for i, e := range seq {
+		chord := chords[i]
+		if chord.mods == chordLeader {
+			chord = m.leader
+		}
+		if !chord.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Keymap) expire(now time.Time) bool {
+	if len(m.pending) == 0 || now.Sub(m.pendingAt) < m.timeout() {
+		return false
+	}
+	return m.runFallback()
+}
+
+// runFallback abandons the pending sequence and runs the binding it shadowed.
+func (m *Keymap) runFallback() bool {
+	b := m.fallback
+	var last *InputEvent
+	if len(m.pending) > 0 {
+		last = m.pending[len(m.pending)-1]
+	}
+	m.reset()
+	if b == nil {
+		return false
+	}
+	b.handler(last)
+	return true
+}
+
+func (m *Keymap) reset() {
+	m.pending, m.fallback = nil, nil
+}
+
+func (m *Keymap) timeout() time.Duration {
+	if m.Timeout <= 0 {
+		return defaultSequenceTimeout
+	}
+	return m.Timeout
+}
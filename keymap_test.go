@@ -0,0 +1,86 @@
+package vtinput
+
+import (
+	"testing"
+	"time"
+)
+
+func keyDown(vk uint16, mods uint32) *InputEvent {
+	return &InputEvent{Type: KeyEventType, VirtualKeyCode: vk, ControlKeyState: mods, KeyDown: true}
+}
+
+func TestKeymap_Sequences(t *testing.T) {
+	var got []string
+	bind := func(l *KeyLayer, seq string) {
+		if err := l.Bind(seq, func(*InputEvent) { got = append(got, seq) }); err != nil {
+			t.Fatalf("Bind(%q): %v", seq, err)
+		}
+	}
+
+	var m Keymap
+	global := &KeyLayer{}
+	bind(global, "Ctrl+X Ctrl+S")
+	bind(global, "Ctrl+X")
+	bind(global, "F2")
+	m.Push(global)
+	start := time.Now()
+
+	// Lock bits are ignored and the Ctrl key press does not break the sequence.
+	m.dispatch(keyDown(VK_X, LeftCtrlPressed|NumLockOn), start)
+	m.dispatch(keyDown(VK_CONTROL, LeftCtrlPressed), start)
+	m.dispatch(keyDown(VK_S, LeftCtrlPressed|CapsLockOn), start)
+
+	// Ctrl+X alone runs once the sequence is not continued, then F2 runs.
+	m.dispatch(keyDown(VK_X, LeftCtrlPressed), start)
+	m.dispatch(keyDown(VK_F2, 0), start)
+
+	// Ctrl+X alone runs on timeout.
+	m.dispatch(keyDown(VK_X, LeftCtrlPressed), start)
+	if d, ok := m.Deadline(); !ok || !d.Equal(start.Add(time.Second)) {
+		t.Errorf("Deadline() = %v, %v", d, ok)
+	}
+	m.expire(start.Add(2 * time.Second))
+
+	want := []string{"Ctrl+X Ctrl+S", "Ctrl+X", "F2", "Ctrl+X"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	if m.dispatch(keyDown(VK_A, 0), start) {
+		t.Errorf("Expected unbound key not to be consumed")
+	}
+}
+
+func TestKeymap_Layers(t *testing.T) {
+	var got string
+	var m Keymap
+	global, dialog := &KeyLayer{}, &KeyLayer{}
+	global.Bind("Esc", func(*InputEvent) { got = "global" })
+	global.Bind("Leader Q", func(*InputEvent) { got = "quit" })
+	dialog.Bind("Esc", func(*InputEvent) { got = "dialog" })
+	m.SetLeader("Ctrl+A")
+
+	m.Push(global)
+	m.Push(dialog)
+	m.Dispatch(keyDown(VK_ESCAPE, 0))
+	if got != "dialog" {
+		t.Errorf("Expected the dialog layer to shadow the global one, got %q", got)
+	}
+
+	m.Remove(dialog)
+	m.Dispatch(keyDown(VK_ESCAPE, 0))
+	if got != "global" {
+		t.Errorf("Expected the global layer after removing the dialog, got %q", got)
+	}
+
+	m.Dispatch(keyDown(VK_A, RightCtrlPressed))
+	m.Dispatch(keyDown(VK_Q, 0))
+	if got != "quit" {
+		t.Errorf("Expected leader sequence to run, got %q", got)
+	}
+}
@@ -0,0 +1,373 @@
+package vtinput
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidKey is returned by ParseKey for malformed chord strings.
+var ErrInvalidKey = errors.New("invalid key chord")
+
+// vkNames holds the canonical key names used by FormatKey and ParseKey.
+// They contain no spaces and no "+" other than at the end, so that chords
+// and sequences of chords can be split unambiguously.
+var vkNames = map[uint16]string{
+	VK_LBUTTON:    "LButton",
+	VK_RBUTTON:    "RButton",
+	VK_CANCEL:     "Cancel",
+	VK_MBUTTON:    "MButton",
+	VK_BACK:       "Backspace",
+	VK_TAB:        "Tab",
+	VK_CLEAR:      "Clear",
+	VK_RETURN:     "Enter",
+	VK_SHIFT:      "Shift",
+	VK_CONTROL:    "Ctrl",
+	VK_MENU:       "Alt",
+	VK_PAUSE:      "Pause",
+	VK_CAPITAL:    "CapsL",
+	VK_ESCAPE:     "Esc",
+	VK_SPACE:      "Space",
+	VK_PRIOR:      "PgUp",
+	VK_NEXT:       "PgDn",
+	VK_END:        "End",
+	VK_HOME:       "Home",
+	VK_LEFT:       "Left",
+	VK_UP:         "Up",
+	VK_RIGHT:      "Right",
+	VK_DOWN:       "Down",
+	VK_SELECT:     "Select",
+	VK_PRINT:      "Print",
+	VK_EXECUTE:    "Execute",
+	VK_SNAPSHOT:   "PrtScn",
+	VK_INSERT:     "Insert",
+	VK_DELETE:     "Delete",
+	VK_HELP:       "Help",
+	VK_0:          "0",
+	VK_1:          "1",
+	VK_2:          "2",
+	VK_3:          "3",
+	VK_4:          "4",
+	VK_5:          "5",
+	VK_6:          "6",
+	VK_7:          "7",
+	VK_8:          "8",
+	VK_9:          "9",
+	VK_A:          "A",
+	VK_B:          "B",
+	VK_C:          "C",
+	VK_D:          "D",
+	VK_E:          "E",
+	VK_F:          "F",
+	VK_G:          "G",
+	VK_H:          "H",
+	VK_I:          "I",
+	VK_J:          "J",
+	VK_K:          "K",
+	VK_L:          "L",
+	VK_M:          "M",
+	VK_N:          "N",
+	VK_O:          "O",
+	VK_P:          "P",
+	VK_Q:          "Q",
+	VK_R:          "R",
+	VK_S:          "S",
+	VK_T:          "T",
+	VK_U:          "U",
+	VK_V:          "V",
+	VK_W:          "W",
+	VK_X:          "X",
+	VK_Y:          "Y",
+	VK_Z:          "Z",
+	VK_LWIN:       "LWin",
+	VK_RWIN:       "RWin",
+	VK_APPS:       "Apps",
+	VK_NUMPAD0:    "Num0",
+	VK_NUMPAD1:    "Num1",
+	VK_NUMPAD2:    "Num2",
+	VK_NUMPAD3:    "Num3",
+	VK_NUMPAD4:    "Num4",
+	VK_NUMPAD5:    "Num5",
+	VK_NUMPAD6:    "Num6",
+	VK_NUMPAD7:    "Num7",
+	VK_NUMPAD8:    "Num8",
+	VK_NUMPAD9:    "Num9",
+	VK_MULTIPLY:   "Num*",
+	VK_ADD:        "Num+",
+	VK_SEPARATOR:  "Num,",
+	VK_SUBTRACT:   "Num-",
+	VK_DECIMAL:    "Num.",
+	VK_DIVIDE:     "Num/",
+	VK_F1:         "F1",
+	VK_F2:         "F2",
+	VK_F3:         "F3",
+	VK_F4:         "F4",
+	VK_F5:         "F5",
+	VK_F6:         "F6",
+	VK_F7:         "F7",
+	VK_F8:         "F8",
+	VK_F9:         "F9",
+	VK_F10:        "F10",
+	VK_F11:        "F11",
+	VK_F12:        "F12",
+	VK_F13:        "F13",
+	VK_F14:        "F14",
+	VK_F15:        "F15",
+	VK_F16:        "F16",
+	VK_F17:        "F17",
+	VK_F18:        "F18",
+	VK_F19:        "F19",
+	VK_F20:        "F20",
+	VK_F21:        "F21",
+	VK_F22:        "F22",
+	VK_F23:        "F23",
+	VK_F24:        "F24",
+	VK_NUMLOCK:    "NumL",
+	VK_SCROLL:     "ScrL",
+	VK_LSHIFT:     "LShift",
+	VK_RSHIFT:     "RShift",
+	VK_LCONTROL:   "LCtrl",
+	VK_RCONTROL:   "RCtrl",
+	VK_LMENU:      "LAlt",
+	VK_RMENU:      "RAlt",
+	VK_OEM_1:      ";",
+	VK_OEM_PLUS:   "=",
+	VK_OEM_COMMA:  ",",
+	VK_OEM_MINUS:  "-",
+	VK_OEM_PERIOD: ".",
+	VK_OEM_2:      "/",
+	VK_OEM_3:      "`",
+	VK_OEM_4:      "[",
+	VK_OEM_5:      "\\",
+	VK_OEM_6:      "]",
+	VK_OEM_7:      "'",
+	VK_OEM_102:    "OEM102",
+}
+
+// keyAliases are extra names accepted by ParseKey.
+var keyAliases = map[string]uint16{
+	"return":     VK_RETURN,
+	"escape":     VK_ESCAPE,
+	"pageup":     VK_PRIOR,
+	"pagedown":   VK_NEXT,
+	"ins":        VK_INSERT,
+	"del":        VK_DELETE,
+	"capslock":   VK_CAPITAL,
+	"numlock":    VK_NUMLOCK,
+	"scrolllock": VK_SCROLL,
+}
+
+// vkByName maps lowercased key names and aliases to virtual key codes.
+var vkByName = func() map[string]uint16 {
+	m := make(map[string]uint16, len(vkNames)+len(keyAliases))
+	for vk, name := range vkNames {
+		m[strings.ToLower(name)] = vk
+	}
+	for name, vk := range keyAliases {
+		m[name] = vk
+	}
+	return m
+}()
+
+// KeyName returns the canonical name of a virtual key code, such as "F5",
+// "PgUp" or "A", or a hex code like "0xE5" for keys without a name.
+func KeyName(vk uint16) string {
+	if name, ok := vkNames[vk]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02X", vk)
+}
+
+// Modifier flags of a KeyChord beyond ControlKeyState: Ctrl or Alt on
+// either side.
+const (
+	chordAnyCtrl = 0x10000
+	chordAnyAlt  = 0x20000
+
+	ctrlKeyState  = LeftCtrlPressed | RightCtrlPressed
+	altKeyState   = LeftAltPressed | RightAltPressed
+	otherKeyState = ShiftPressed | SuperPressed | HyperPressed | MetaPressed
+)
+
+// chordModifiers lists modifier names in canonical order with their flags.
+var chordModifiers = []struct {
+	name string
+	flag uint32
+}{
+	{"Ctrl", chordAnyCtrl},
+	{"LCtrl", LeftCtrlPressed},
+	{"RCtrl", RightCtrlPressed},
+	{"Alt", chordAnyAlt},
+	{"LAlt", LeftAltPressed},
+	{"RAlt", RightAltPressed},
+	{"Shift", ShiftPressed},
+	{"Super", SuperPressed},
+	{"Hyper", HyperPressed},
+	{"Meta", MetaPressed},
+}
+
+// KeyChord is a key with modifiers, written like "Ctrl+Shift+F5" or
+// "RAlt+Enter". "Ctrl" and "Alt" match either side, "LCtrl", "RAlt" etc.
+// only the named one. The zero value matches nothing.
+type KeyChord struct {
+	vk   uint16
+	char rune   // set instead of vk for characters without a key name
+	mods uint32 // ControlKeyState modifier flags, plus chordAnyCtrl/chordAnyAlt
+}
+
+// ParseKey parses a chord in the notation produced by FormatKey. Names are
+// case-insensitive. Keys without a name are given as the character they
+// type (e.g. "Alt+é") or as a hex virtual key code (e.g. "0xE5").
+func ParseKey(s string) (KeyChord, error) {
+	var chord KeyChord
+	rest := s
+	for {
+		// The key name is whatever follows the last modifier, which lets
+		// "Ctrl++" and "Num+" through.
+		i := strings.IndexByte(rest, '+')
+		if i <= 0 || i == len(rest)-1 {
+			break
+		}
+		flag, ok := modifierByName(rest[:i])
+		if !ok {
+			break
+		}
+		chord.mods |= flag
+		rest = rest[i+1:]
+	}
+
+	if vk, ok := vkByName[strings.ToLower(rest)]; ok {
+		chord.vk = vk
+	} else if strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X") {
+		vk, err := strconv.ParseUint(rest[2:], 16, 16)
+		if err != nil || vk == 0 {
+			return KeyChord{}, fmt.Errorf("%w: %q", ErrInvalidKey, s)
+		}
+		chord.vk = uint16(vk)
+	} else if runes := []rune(rest); len(runes) == 1 && runes[0] > ' ' {
+		chord.char = runes[0]
+	} else {
+		return KeyChord{}, fmt.Errorf("%w: %q", ErrInvalidKey, s)
+	}
+	return chord, nil
+}
+
+// FormatKey returns the chord notation of a key event, e.g. "Ctrl+Shift+F5".
+// Lock keys and EnhancedKey are not part of it. A modifier key reports its own
+// side, e.g. "LShift" rather than "Shift+LShift". Other events yield "".
+func FormatKey(e *InputEvent) string {
+	if e.Type != KeyEventType {
+		return ""
+	}
+	chord := chordOf(e)
+	switch chord.mods & ctrlKeyState {
+	case LeftCtrlPressed:
+		chord.mods ^= LeftCtrlPressed | chordAnyCtrl
+	}
+	switch chord.mods & altKeyState {
+	case LeftAltPressed:
+		chord.mods ^= LeftAltPressed | chordAnyAlt
+	}
+	return chord.String()
+}
+
+// String returns the chord in canonical notation.
+func (k KeyChord) String() string {
+	var b strings.Builder
+	for _, m := range chordModifiers {
+		if k.mods&m.flag != 0 {
+			b.WriteString(m.name)
+			b.WriteByte('+')
+		}
+	}
+	if k.char != 0 {
+		b.WriteRune(k.char)
+	} else {
+		b.WriteString(KeyName(k.vk))
+	}
+	return b.String()
+}
+
+// Match reports whether e is a key event of the chord. KeyDown is not
+// considered, and neither are lock keys.
+func (k KeyChord) Match(e *InputEvent) bool {
+	if e.Type != KeyEventType || (k.vk == 0 && k.char == 0) {
+		return false
+	}
+	ev := chordOf(e)
+	if k.char != 0 {
+		if ev.char != k.char {
+			return false
+		}
+	} else if ev.vk != k.vk && genericModifierKey(ev.vk) != k.vk {
+		return false
+	}
+
+	if k.mods&chordAnyCtrl != 0 {
+		if ev.mods&ctrlKeyState == 0 {
+			return false
+		}
+	} else if ev.mods&ctrlKeyState != k.mods&ctrlKeyState {
+		return false
+	}
+	if k.mods&chordAnyAlt != 0 {
+		if ev.mods&altKeyState == 0 {
+			return false
+		}
+	} else if ev.mods&altKeyState != k.mods&altKeyState {
+		return false
+	}
+	return ev.mods&otherKeyState == k.mods&otherKeyState
+}
+
+// chordOf describes a key event as a chord with exact modifier flags.
+func chordOf(e *InputEvent) KeyChord {
+	chord := KeyChord{mods: e.ControlKeyState & (ctrlKeyState | altKeyState | otherKeyState)}
+	switch {
+	case e.VirtualKeyCode != 0 && (e.VirtualKeyCode != VK_UNASSIGNED || e.Char == 0):
+		chord.vk = sidedModifierKey(e)
+		// A modifier key event usually carries its own flag; drop it.
+		chord.mods &^= modifierBit(chord.vk)
+	case e.Char == ' ':
+		chord.vk = VK_SPACE
+	case e.Char == 0x7F:
+		chord.vk = VK_BACK
+	case e.Char > 0 && e.Char < ' ':
+		// A control character stands for the key that produced it.
+		if t := translateLegacyByte(e.Char); t != nil {
+			chord.vk = t.VirtualKeyCode
+			chord.mods |= t.ControlKeyState
+		}
+	case e.Char != 0:
+		if vk, ok := vkByName[strings.ToLower(string(e.Char))]; ok {
+			chord.vk = vk
+		} else {
+			chord.char = e.Char
+		}
+	}
+	return chord
+}
+
+// modifierByName looks up a modifier name case-insensitively.
+func modifierByName(name string) (uint32, bool) {
+	for _, m := range chordModifiers {
+		if strings.EqualFold(name, m.name) {
+			return m.flag, true
+		}
+	}
+	return 0, false
+}
+
+// genericModifierKey maps a sided modifier key to its generic code.
+func genericModifierKey(vk uint16) uint16 {
+	switch vk {
+	case VK_LSHIFT, VK_RSHIFT:
+		return VK_SHIFT
+	case VK_LCONTROL, VK_RCONTROL:
+		return VK_CONTROL
+	case VK_LMENU, VK_RMENU:
+		return VK_MENU
+	}
+	return vk
+}
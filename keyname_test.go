@@ -0,0 +1,83 @@
+package vtinput
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatKey(t *testing.T) {
+	tests := []struct {
+		event InputEvent
+		want  string
+	}{
+		{InputEvent{VirtualKeyCode: VK_F5, ControlKeyState: LeftCtrlPressed | ShiftPressed | NumLockOn}, "Ctrl+Shift+F5"},
+		{InputEvent{VirtualKeyCode: VK_RETURN, ControlKeyState: RightAltPressed | EnhancedKey}, "RAlt+Enter"},
+		{InputEvent{VirtualKeyCode: VK_A, ControlKeyState: LeftCtrlPressed | RightCtrlPressed}, "LCtrl+RCtrl+A"},
+		{InputEvent{Char: 'x', ControlKeyState: LeftAltPressed, IsLegacy: true}, "Alt+X"},
+		{InputEvent{Char: '+', ControlKeyState: LeftCtrlPressed}, "Ctrl++"},
+		{InputEvent{Char: 'é'}, "é"},
+		{InputEvent{VirtualKeyCode: VK_ADD, ControlKeyState: LeftCtrlPressed}, "Ctrl+Num+"},
+		{InputEvent{VirtualKeyCode: VK_SHIFT, VirtualScanCode: ScanCodeRightShift, ControlKeyState: ShiftPressed}, "RShift"},
+		{InputEvent{VirtualKeyCode: VK_CONTROL, ControlKeyState: LeftCtrlPressed | ShiftPressed}, "Shift+LCtrl"},
+		{InputEvent{VirtualKeyCode: 0xE5}, "0xE5"},
+		{InputEvent{VirtualKeyCode: VK_UNASSIGNED, Char: 'é', ControlKeyState: LeftCtrlPressed}, "Ctrl+é"},
+		{InputEvent{Char: 0x13, ControlKeyState: LeftAltPressed, IsLegacy: true}, "Ctrl+Alt+S"},
+		{InputEvent{Char: ' ', ControlKeyState: LeftAltPressed, IsLegacy: true}, "Alt+Space"},
+	}
+	for _, tt := range tests {
+		tt.event.Type = KeyEventType
+		got := FormatKey(&tt.event)
+		if got != tt.want {
+			t.Errorf("FormatKey(%v) = %q, want %q", tt.event, got, tt.want)
+			continue
+		}
+		chord, err := ParseKey(got)
+		if err != nil || chord.String() != got || !chord.Match(&tt.event) {
+			t.Errorf("ParseKey(%q) = (%v, %v) does not round-trip", got, chord, err)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	chord, err := ParseKey("ctrl+shift+pageup")
+	if err != nil || chord.String() != "Ctrl+Shift+PgUp" {
+		t.Fatalf("ParseKey = (%v, %v)", chord, err)
+	}
+
+	match := []InputEvent{
+		{VirtualKeyCode: VK_PRIOR, ControlKeyState: LeftCtrlPressed | ShiftPressed},
+		{VirtualKeyCode: VK_PRIOR, ControlKeyState: RightCtrlPressed | ShiftPressed | CapsLockOn | EnhancedKey, KeyDown: true},
+	}
+	for _, e := range match {
+		e.Type = KeyEventType
+		if !chord.Match(&e) {
+			t.Errorf("Expected %v to match %v", chord, e)
+		}
+	}
+	noMatch := []InputEvent{
+		{VirtualKeyCode: VK_PRIOR, ControlKeyState: LeftCtrlPressed},
+		{VirtualKeyCode: VK_PRIOR, ControlKeyState: LeftCtrlPressed | ShiftPressed | LeftAltPressed},
+		{VirtualKeyCode: VK_NEXT, ControlKeyState: LeftCtrlPressed | ShiftPressed},
+	}
+	for _, e := range noMatch {
+		e.Type = KeyEventType
+		if chord.Match(&e) {
+			t.Errorf("Expected %v not to match %v", chord, e)
+		}
+	}
+
+	rctrl, _ := ParseKey("RCtrl+C")
+	if rctrl.Match(&InputEvent{Type: KeyEventType, VirtualKeyCode: VK_C, ControlKeyState: LeftCtrlPressed}) {
+		t.Errorf("Expected RCtrl+C not to match left Ctrl")
+	}
+	shift, _ := ParseKey("Shift")
+	if !shift.Match(&InputEvent{Type: KeyEventType, VirtualKeyCode: VK_SHIFT, VirtualScanCode: ScanCodeLeftShift}) {
+		t.Errorf("Expected Shift to match the left Shift key")
+	}
+
+	for _, s := range []string{"", "Ctrl+", "Ctrl+Foo", "0xZZ", "Hello"} {
+		if _, err := ParseKey(s); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKey(%q) error = %v, want ErrInvalidKey", s, err)
+		}
+	}
+}
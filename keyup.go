@@ -0,0 +1,69 @@
+package vtinput
+
+import (
+	"context"
+	"time"
+)
+
+// readWithKeyUp reads the next event while a synthetic key-up is pending,
+// returning the key-up instead if KeyUpTimeout passes first. Reading stops
+// on a partial sequence the same way as for a cancelled ReadEventContext.
+func (r *Reader) readWithKeyUp(ctx context.Context) (*InputEvent, error) {
+	wait := r.opts.KeyUpTimeout - time.Since(r.keyUpAt)
+	if wait <= 0 {
+		return r.releaseKey(), nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	event, err := r.readEvent(waitCtx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// Timed out, or input ended; either way the key is up by now.
+		// A lasting error is reported again by the next read.
+		return r.releaseKey(), nil
+	}
+	return event, nil
+}
+
+// pairKeyUp tracks legacy key-downs for SynthesizeKeyUp. It returns the event
+// to deliver now: the pending key-up if event is a different key, in which
+// case event itself is held back for the next read.
+func (r *Reader) pairKeyUp(event *InputEvent) *InputEvent {
+	if event.Type != KeyEventType {
+		return event
+	}
+	if up := r.keyUp; up != nil {
+		if event.KeyDown && event.IsLegacy && event.VirtualKeyCode == up.VirtualKeyCode && event.Char == up.Char {
+			// Auto-repeat of the held key: it stays down.
+			r.keyUpAt = time.Now()
+			return event
+		}
+		r.releaseKey()
+		r.deferred = event
+		r.trackKeyDown(event)
+		return up
+	}
+	r.trackKeyDown(event)
+	return event
+}
+
+// trackKeyDown schedules a synthetic key-up for a legacy key-down.
+func (r *Reader) trackKeyDown(event *InputEvent) {
+	if !event.KeyDown || !event.IsLegacy {
+		return
+	}
+	up := *event
+	up.KeyDown = false
+	up.IsSynthetic = true
+	r.keyUp, r.keyUpAt = &up, time.Now()
+}
+
+// releaseKey returns the pending synthetic key-up and clears it.
+func (r *Reader) releaseKey() *InputEvent {
+	up := r.keyUp
+	r.keyUp = nil
+	return up
+}
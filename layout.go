@@ -0,0 +1,63 @@
+package vtinput
+
+// LayoutKey describes the key that types a character.
+type LayoutKey struct {
+	VirtualKeyCode uint16
+	// Shift is set if the character needs Shift, like 'A' or '!'.
+	Shift bool
+	// Unshifted is the character the key types without modifiers.
+	Unshifted rune
+}
+
+// Layout maps the characters of a keyboard layout to the keys typing them.
+// Legacy protocols send only characters; the Reader uses a Layout to give
+// such events the VirtualKeyCode, ShiftPressed and UnshiftedChar that kitty
+// and win32 input mode report, so bindings match the same way (see
+// ReaderOptions.Layout). Characters missing from the map are left as is.
+type Layout map[rune]LayoutKey
+
+// LayoutUS is the US QWERTY layout, the default for legacy input.
+var LayoutUS = newLayout(
+	"`~1!2@3#4$5%6^7&8*9(0)-_=+[{]}\\|;:'\",<.>/?",
+	[]uint16{
+		VK_OEM_3, VK_1, VK_2, VK_3, VK_4, VK_5, VK_6, VK_7, VK_8, VK_9, VK_0,
+		VK_OEM_MINUS, VK_OEM_PLUS, VK_OEM_4, VK_OEM_6, VK_OEM_5, VK_OEM_1,
+		VK_OEM_7, VK_OEM_COMMA, VK_OEM_PERIOD, VK_OEM_2,
+	},
+)
+
+// newLayout builds a layout from pairs of unshifted and shifted characters
+// typed by keys, plus the letters A-Z and Space.
+func newLayout(pairs string, keys []uint16) Layout {
+	l := Layout{' ': {VirtualKeyCode: VK_SPACE, Unshifted: ' '}}
+	for c := 'a'; c <= 'z'; c++ {
+		vk := uint16(VK_A + (c - 'a'))
+		l[c] = LayoutKey{VirtualKeyCode: vk, Unshifted: c}
+		l[c-'a'+'A'] = LayoutKey{VirtualKeyCode: vk, Shift: true, Unshifted: c}
+	}
+	chars := []rune(pairs)
+	for i, vk := range keys {
+		plain, shifted := chars[2*i], chars[2*i+1]
+		l[plain] = LayoutKey{VirtualKeyCode: vk, Unshifted: plain}
+		l[shifted] = LayoutKey{VirtualKeyCode: vk, Shift: true, Unshifted: plain}
+	}
+	return l
+}
+
+// Apply fills in the key of a legacy character event that lacks one. With
+// CapsLock on, letters are indistinguishable from shifted ones and get
+// ShiftPressed too.
+func (l Layout) Apply(e *InputEvent) {
+	if e.Type != KeyEventType || e.VirtualKeyCode != 0 || e.Char == 0 {
+		return
+	}
+	key, ok := l[e.Char]
+	if !ok {
+		return
+	}
+	e.VirtualKeyCode = key.VirtualKeyCode
+	e.UnshiftedChar = key.Unshifted
+	if key.Shift {
+		e.ControlKeyState |= ShiftPressed
+	}
+}
@@ -0,0 +1,41 @@
+package vtinput
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReader_LegacyLayout(t *testing.T) {
+	tests := []struct {
+		input     string
+		vk        uint16
+		mods      uint32
+		unshifted rune
+	}{
+		{"a", VK_A, 0, 'a'},
+		{"A", VK_A, ShiftPressed, 'a'},
+		{"5", VK_5, 0, '5'},
+		{"%", VK_5, ShiftPressed, '5'},
+		{"?", VK_OEM_2, ShiftPressed, '/'},
+		{"\x1bS", VK_S, LeftAltPressed | ShiftPressed, 's'},
+		{"я", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		e, err := NewReader(bytes.NewReader([]byte(tt.input))).ReadEvent()
+		if err != nil || e.VirtualKeyCode != tt.vk || e.ControlKeyState != tt.mods || e.UnshiftedChar != tt.unshifted {
+			t.Errorf("%q: got (%+v, %v), want VK 0x%X mods 0x%X base %q", tt.input, e, err, tt.vk, tt.mods, tt.unshifted)
+		}
+	}
+
+	// A legacy Shift+A now matches the same chord as kitty's.
+	e, _ := NewReader(bytes.NewReader([]byte("A"))).ReadEvent()
+	if chord, _ := ParseKey("Shift+A"); !chord.Match(e) {
+		t.Errorf("Expected legacy 'A' to match Shift+A, got %v", FormatKey(e))
+	}
+
+	// An empty layout turns inference off.
+	e, err := NewReaderWithOptions(bytes.NewReader([]byte("A")), ReaderOptions{Layout: Layout{}}).ReadEvent()
+	if err != nil || e.VirtualKeyCode != 0 || e.ControlKeyState != 0 {
+		t.Errorf("Expected untouched legacy event, got (%+v, %v)", e, err)
+	}
+}
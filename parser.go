@@ -14,7 +14,8 @@ var (
 	// ErrIncomplete indicates the sequence might be valid but is incomplete (needs more bytes).
 	ErrIncomplete = errors.New("incomplete sequence")
 )
-// scanCSI looks for a CSI sequence (ESC [ ... terminator).
+// scanCSI looks for a CSI sequence (ESC [ ... terminator). The 8-bit form
+// (0x9B) is rewritten to this one by the Reader (ReaderOptions.C1Controls).
 func scanCSI(data []byte) (terminatorIdx int, command byte, err error) {
 	if len(data) < 2 {
 		return 0, 0, ErrIncomplete
@@ -38,14 +39,21 @@ func scanCSI(data []byte) (terminatorIdx int, command byte, err error) {
 	return 0, 0, ErrIncomplete
 }
 // decodeAnsiModifiers converts TUI modifier codes (1 + bitmask) to vtinput flags.
-// Supported by Kitty and modern Legacy CSI.
+// Supported by Kitty and modern Legacy CSI. Bits follow the kitty protocol:
+// Super, Hyper and Meta are reported as such, lock bits only come from kitty.
+// xterm uses bit 8 for Meta instead; that is reported as Super too, since the
+// sequences alone do not tell the two apart.
 func decodeAnsiModifiers(modCode int) uint32 {
 	actual := uint32(0)
 	bits := modCode - 1
 	if (bits & 0x01) != 0 { actual |= ShiftPressed }
 	if (bits & 0x02) != 0 { actual |= LeftAltPressed }
 	if (bits & 0x04) != 0 { actual |= LeftCtrlPressed }
-	if (bits & 0x08) != 0 { actual |= EnhancedKey } // Super/Win in Kitty
+	if (bits & 0x08) != 0 { actual |= SuperPressed }
+	if (bits & 0x10) != 0 { actual |= HyperPressed }
+	if (bits & 0x20) != 0 { actual |= MetaPressed }
+	if (bits & 0x40) != 0 { actual |= CapsLockOn }
+	if (bits & 0x80) != 0 { actual |= NumLockOn }
 	return actual
 }
 
@@ -79,6 +87,11 @@ func mapTildeToVK(code int) uint16 {
 	case 11, 12, 13, 14, 15: return uint16(VK_F1 + (code - 11))
 	case 17, 18, 19, 20, 21: return uint16(VK_F6 + (code - 17))
 	case 23, 24:             return uint16(VK_F11 + (code - 23))
+	// VT220 and xterm: F13-F20, with Help and Do in the places of F15 and F16.
+	case 25, 26:             return uint16(VK_F13 + (code - 25))
+	case 28:                 return VK_HELP
+	case 29:                 return VK_EXECUTE // Do
+	case 31, 32, 33, 34:     return uint16(VK_F17 + (code - 31))
 	}
 	return 0
 }
@@ -136,6 +149,23 @@ func ParseLegacyCSI(data []byte) (*InputEvent, int, error) {
 		return nil, 0, err
 	}
 
+	if command == '[' && terminatorIdx == 2 {
+		// Linux console: F1-F5 are CSI [ A to CSI [ E.
+		if len(data) < 4 {
+			return nil, 0, ErrIncomplete
+		}
+		if data[3] < 'A' || data[3] > 'E' {
+			return nil, 0, ErrInvalidSequence
+		}
+		event := &InputEvent{
+			Type:           KeyEventType,
+			VirtualKeyCode: uint16(VK_F1 + (data[3] - 'A')),
+			KeyDown:        true,
+			IsLegacy:       true,
+		}
+		return event, 4, nil
+	}
+
 	params := strings.Split(string(data[2:terminatorIdx]), ";")
 	getParam := func(idx int, def int) int {
 		if len(params) <= idx || params[idx] == "" { return def }
@@ -165,8 +195,155 @@ func ParseLegacyCSI(data []byte) (*InputEvent, int, error) {
 
 	return event, terminatorIdx + 1, nil
 }
+// ParseModifyOtherKeys handles keys reported by xterm's modifyOtherKeys mode
+// in its default encoding (formatOtherKeys=0): CSI 27 ; modifiers ; code ~,
+// where code is the character of the key. The CSI code ; modifiers u form
+// (formatOtherKeys=1) is handled by ParseKitty.
+func ParseModifyOtherKeys(data []byte) (*InputEvent, int, error) {
+	terminatorIdx, command, err := scanCSI(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params := strings.Split(string(data[2:terminatorIdx]), ";")
+	if command != '~' || len(params) != 3 || params[0] != "27" {
+		return nil, 0, ErrInvalidSequence
+	}
+	mod, err := strconv.Atoi(params[1])
+	if err != nil || mod < 1 {
+		return nil, 0, ErrInvalidSequence
+	}
+	code, err := strconv.Atoi(params[2])
+	if err != nil || code <= 0 || !utf8.ValidRune(rune(code)) {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	event := &InputEvent{
+		Type:            KeyEventType,
+		KeyDown:         true,
+		RepeatCount:     1,
+		ControlKeyState: decodeAnsiModifiers(mod),
+		IsLegacy:        true,
+	}
+	switch code {
+	case 8, 127: event.VirtualKeyCode = VK_BACK
+	case 9: event.VirtualKeyCode = VK_TAB
+	case 13: event.VirtualKeyCode = VK_RETURN
+	case 27: event.VirtualKeyCode = VK_ESCAPE
+	default:
+		if code < 32 {
+			return nil, 0, ErrInvalidSequence
+		}
+		// The key is left for the Reader's Layout to fill in.
+		event.Char = rune(code)
+	}
+
+	return event, terminatorIdx + 1, nil
+}
+
+// ParseRxvt handles the modified keys of rxvt and urxvt, which mark modifiers
+// with the final character instead of a parameter: CSI n $ (Shift), CSI n ^
+// (Ctrl) and CSI n @ (Ctrl+Shift) for keys with a CSI n ~ code, CSI a-d for
+// Shift+Up/Down/Right/Left and ESC O a-d for Ctrl+Up/Down/Right/Left.
+func ParseRxvt(data []byte) (*InputEvent, int, error) {
+	if len(data) < 2 {
+		return nil, 0, ErrIncomplete
+	}
+	if data[0] != 0x1B {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	event := &InputEvent{
+		Type:     KeyEventType,
+		KeyDown:  true,
+		IsLegacy: true,
+	}
+
+	if data[1] == 'O' {
+		if len(data) < 3 {
+			return nil, 0, ErrIncomplete
+		}
+		if event.VirtualKeyCode = mapRxvtArrowToVK(data[2]); event.VirtualKeyCode == 0 {
+			return nil, 0, ErrInvalidSequence
+		}
+		event.ControlKeyState = LeftCtrlPressed
+		return event, 3, nil
+	}
+
+	if data[1] != '[' {
+		return nil, 0, ErrInvalidSequence
+	}
+	// The '$' final is an intermediate byte to ECMA-48, so scanCSI can't find
+	// the end; rxvt keys only ever have a run of digits before the final.
+	terminatorIdx := skipDigits(data, 2)
+	if terminatorIdx == len(data) {
+		return nil, 0, ErrIncomplete
+	}
+	command := data[terminatorIdx]
+	params := string(data[2:terminatorIdx])
+
+	switch command {
+	case '$': event.ControlKeyState = ShiftPressed
+	case '^': event.ControlKeyState = LeftCtrlPressed
+	case '@': event.ControlKeyState = LeftCtrlPressed | ShiftPressed
+	default:
+		if params != "" {
+			return nil, 0, ErrInvalidSequence
+		}
+		if event.VirtualKeyCode = mapRxvtArrowToVK(command); event.VirtualKeyCode == 0 {
+			return nil, 0, ErrInvalidSequence
+		}
+		event.ControlKeyState = ShiftPressed
+		return event, terminatorIdx + 1, nil
+	}
+
+	code, err := strconv.Atoi(params)
+	if err != nil {
+		return nil, 0, ErrInvalidSequence
+	}
+	if event.VirtualKeyCode = mapTildeToVK(code); event.VirtualKeyCode == 0 {
+		return nil, 0, ErrInvalidSequence
+	}
+	return event, terminatorIdx + 1, nil
+}
+
+// scanCSIOrRxvt is scanCSI that also ends a sequence at the '$' of an rxvt
+// Shift-modified key (CSI digits $), where ECMA-48 expects more to follow.
+func scanCSIOrRxvt(data []byte) (terminatorIdx int, command byte, err error) {
+	terminatorIdx, command, err = scanCSI(data)
+	if err == ErrInvalidSequence {
+		return
+	}
+	if i := skipDigits(data, 2); i > 2 && i < len(data) && data[i] == '$' {
+		return i, '$', nil
+	}
+	return
+}
+
+// skipDigits returns the index of the first non-digit in data at or after i.
+func skipDigits(data []byte, i int) int {
+	for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// mapRxvtArrowToVK maps the lowercase finals of rxvt modified arrows.
+func mapRxvtArrowToVK(command byte) uint16 {
+	switch command {
+	case 'a': return VK_UP
+	case 'b': return VK_DOWN
+	case 'c': return VK_RIGHT
+	case 'd': return VK_LEFT
+	}
+	return 0
+}
+
 // ParseLegacySS3 handles standard SS3 sequences (ESC O ...).
-// These are common for F1-F4 and Home/End on some terminals.
+// These are common for F1-F4 and Home/End on some terminals, for cursor keys
+// in application cursor mode, and for the numeric keypad in application
+// keypad mode (DECKPAM). Older xterms put a modifier code between O and the
+// final character (ESC O 5 P for Ctrl+F1).
 func ParseLegacySS3(data []byte) (*InputEvent, int, error) {
 	if len(data) < 2 {
 		return nil, 0, ErrIncomplete
@@ -174,7 +351,17 @@ func ParseLegacySS3(data []byte) (*InputEvent, int, error) {
 	if data[0] != 0x1B || data[1] != 'O' {
 		return nil, 0, ErrInvalidSequence
 	}
-	if len(data) < 3 {
+
+	// Optional modifier code.
+	i := 2
+	mod := 0
+	for ; i < len(data) && data[i] >= '0' && data[i] <= '9'; i++ {
+		mod = mod*10 + int(data[i]-'0')
+		if mod > 255 {
+			return nil, 0, ErrInvalidSequence
+		}
+	}
+	if i >= len(data) {
 		return nil, 0, ErrIncomplete
 	}
 
@@ -183,19 +370,51 @@ func ParseLegacySS3(data []byte) (*InputEvent, int, error) {
 		KeyDown:  true,
 		IsLegacy: true,
 	}
+	if i > 2 {
+		event.ControlKeyState = decodeAnsiModifiers(mod)
+	}
 
-	switch data[2] {
+	switch final := data[i]; final {
 	case 'P': event.VirtualKeyCode = VK_F1
 	case 'Q': event.VirtualKeyCode = VK_F2
 	case 'R': event.VirtualKeyCode = VK_F3
 	case 'S': event.VirtualKeyCode = VK_F4
 	case 'H': event.VirtualKeyCode = VK_HOME
 	case 'F': event.VirtualKeyCode = VK_END
+	case 'A': event.VirtualKeyCode = VK_UP
+	case 'B': event.VirtualKeyCode = VK_DOWN
+	case 'C': event.VirtualKeyCode = VK_RIGHT
+	case 'D': event.VirtualKeyCode = VK_LEFT
+	case 'E': event.VirtualKeyCode = VK_CLEAR // Keypad 5 without NumLock
 	default:
-		return nil, 0, ErrInvalidSequence
+		vk, char := mapKeypadSS3(final)
+		if vk == 0 {
+			return nil, 0, ErrInvalidSequence
+		}
+		event.VirtualKeyCode, event.Char = vk, char
+		if vk == VK_RETURN {
+			event.ControlKeyState |= EnhancedKey // Keypad Enter
+		}
 	}
 
-	return event, 3, nil
+	return event, i + 1, nil
+}
+
+// mapKeypadSS3 maps the finals of application keypad keys to the key and the
+// character it types.
+func mapKeypadSS3(final byte) (uint16, rune) {
+	switch final {
+	case 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y':
+		return uint16(VK_NUMPAD0 + (final - 'p')), rune('0' + (final - 'p'))
+	case 'j': return VK_MULTIPLY, '*'
+	case 'k': return VK_ADD, '+'
+	case 'l': return VK_SEPARATOR, ','
+	case 'm': return VK_SUBTRACT, '-'
+	case 'n': return VK_DECIMAL, '.'
+	case 'o': return VK_DIVIDE, '/'
+	case 'M': return VK_RETURN, '\r'
+	}
+	return 0, 0
 }
 // ParseMouseSGR handles modern SGR mouse sequences (ESC [ < ...).
 func ParseMouseSGR(data []byte) (*InputEvent, int, error) {
@@ -235,25 +454,61 @@ func ParseMouseSGR(data []byte) (*InputEvent, int, error) {
 		MouseY:  uint16(py),
 		KeyDown: (command == 'M'), // 'M' = press/move, 'm' = release
 	}
+	decodeMouseButton(pb, event)
+
+	return event, terminatorIdx + 1, nil
+}
 
+// ParseMouseSGRPixels handles SGR-Pixels mouse sequences (mode 1016). They
+// look exactly like SGR ones, so the caller must know which mode is active.
+// Coordinates are stored in MousePixelX/MousePixelY; MouseX/MouseY are zero.
+func ParseMouseSGRPixels(data []byte) (*InputEvent, int, error) {
+	event, consumed, err := ParseMouseSGR(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	event.MousePixelX, event.MousePixelY = event.MouseX, event.MouseY
+	event.MouseX, event.MouseY = 0, 0
+	return event, consumed, nil
+}
+
+// PixelsToCells converts a 0-based pixel position into the 0-based column
+// and row of the cell containing it, given the cell size in pixels as
+// reported for CSI 16t. It returns 0, 0 if the cell size is unknown.
+func PixelsToCells(px, py, cellWidth, cellHeight int) (col, row int) {
+	if cellWidth <= 0 || cellHeight <= 0 {
+		return 0, 0
+	}
+	return px / cellWidth, py / cellHeight
+}
+
+// decodeMouseButton fills button, wheel, motion and modifier fields of a mouse
+// event from the xterm button code shared by the SGR, X10 and urxvt encodings.
+func decodeMouseButton(pb int, event *InputEvent) {
 	// Decode Pb bits:
 	// 0-1: button (0=Left, 1=Middle, 2=Right, 3=Release/None)
-	// 5: motion, 6: wheel
-	buttonPart := pb & 0x03
-	if (pb & 64) != 0 {
-		// Mouse Wheel
-		if buttonPart == 0 {
-			event.WheelDirection = 1 // Up
-		} else if buttonPart == 1 {
-			event.WheelDirection = -1 // Down
+	// 5: motion, 6: buttons 4-7 (wheel), 7: buttons 8-11 (extra)
+	button := (pb & 0x03) | (pb&0xC0)>>4
+	switch button {
+	case 0: event.ButtonState = FromLeft1stButtonPressed
+	case 1: event.ButtonState = FromLeft2ndButtonPressed
+	case 2: event.ButtonState = RightmostButtonPressed
+	case 4, 5: // Vertical wheel: up, down
+		event.MouseEventFlags |= MouseWheeled
+		event.WheelDirection = 1
+		if button == 5 {
+			event.WheelDirection = -1
 		}
-	} else {
-		// Normal buttons
-		switch buttonPart {
-		case 0: event.ButtonState = FromLeft1stButtonPressed
-		case 1: event.ButtonState = FromLeft2ndButtonPressed
-		case 2: event.ButtonState = RightmostButtonPressed
+	case 6, 7: // Horizontal wheel: left, right
+		event.MouseEventFlags |= MouseHWheeled
+		event.WheelDirection = -1
+		if button == 7 {
+			event.WheelDirection = 1
 		}
+	case 8: event.ButtonState = FromLeft3rdButtonPressed  // Back
+	case 9: event.ButtonState = FromLeft4thButtonPressed  // Forward
+	case 10: event.ButtonState = FromLeft5thButtonPressed
+	case 11: event.ButtonState = FromLeft6thButtonPressed
 	}
 
 	if (pb & 32) != 0 {
@@ -264,9 +519,139 @@ func ParseMouseSGR(data []byte) (*InputEvent, int, error) {
 	if (pb & 4) != 0 { event.ControlKeyState |= ShiftPressed }
 	if (pb & 8) != 0 { event.ControlKeyState |= LeftAltPressed }
 	if (pb & 16) != 0 { event.ControlKeyState |= LeftCtrlPressed }
+}
 
-	return event, terminatorIdx + 1, nil
+// ParseMouseX10 handles classic xterm mouse reports (ESC [ M Cb Cx Cy), sent
+// when the terminal ignores SGR mode 1006. Each value is offset by 32 and
+// 1-based. With utf8Coords (mode 1005) the values are UTF-8 encoded instead of
+// raw bytes, which lifts the 223 column limit to 2015. A position beyond the
+// limit arrives as a NUL byte and is reported at the limit.
+func ParseMouseX10(data []byte, utf8Coords bool) (*InputEvent, int, error) {
+	terminatorIdx, command, err := scanCSI(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if command != 'M' || terminatorIdx != 2 {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	var values [3]int
+	pos := 3
+	for i := range values {
+		if pos >= len(data) {
+			return nil, 0, ErrIncomplete
+		}
+		if utf8Coords && data[pos] >= utf8.RuneSelf {
+			if !utf8.FullRune(data[pos:]) {
+				return nil, 0, ErrIncomplete
+			}
+			r, size := utf8.DecodeRune(data[pos:])
+			if r == utf8.RuneError {
+				return nil, 0, ErrInvalidSequence
+			}
+			values[i] = int(r) - 32
+			pos += size
+		} else {
+			values[i] = int(data[pos]) - 32
+			pos++
+		}
+	}
+	if values[0] < 0 {
+		return nil, 0, ErrInvalidSequence
+	}
+	limit := 255 - 32
+	if utf8Coords {
+		limit = 2047 - 32
+	}
+	for i := 1; i < len(values); i++ {
+		if values[i] < 1 {
+			values[i] = limit
+		}
+	}
+
+	return newLegacyMouseEvent(values[0], values[1], values[2]), pos, nil
 }
+
+// ParseMouseURXVT handles urxvt-style mouse reports (mode 1015):
+// ESC [ Cb ; Cx ; Cy M, with decimal values and Cb offset by 32 as in X10.
+func ParseMouseURXVT(data []byte) (*InputEvent, int, error) {
+	terminatorIdx, command, err := scanCSI(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if command != 'M' {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	params := strings.Split(string(data[2:terminatorIdx]), ";")
+	if len(params) != 3 {
+		return nil, 0, ErrInvalidSequence
+	}
+	var values [3]int
+	for i, p := range params {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 1 || v > 0xFFFF {
+			return nil, 0, ErrInvalidSequence
+		}
+		values[i] = v
+	}
+	if values[0] < 32 {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	return newLegacyMouseEvent(values[0]-32, values[1], values[2]), terminatorIdx + 1, nil
+}
+
+// newLegacyMouseEvent builds a mouse event from an X10-style report, where a
+// release is signalled by button code 3 rather than a separate terminator.
+func newLegacyMouseEvent(pb, px, py int) *InputEvent {
+	// Reports are 1-based, we use 0-based coordinates.
+	if px > 0 { px-- }
+	if py > 0 { py-- }
+
+	event := &InputEvent{
+		Type:   MouseEventType,
+		MouseX: uint16(px),
+		MouseY: uint16(py),
+	}
+	decodeMouseButton(pb, event)
+	event.KeyDown = (pb & 0x03) != 3 || (pb & (32 | 64 | 128)) != 0
+
+	return event
+}
+// ParseCursorPosition handles a Cursor Position Report, the reply to DSR 6
+// (CSI row ; col R), or its DEC variant (CSI ? row ; col [; page] R).
+// Note that CSI 1;<mod> R is also how legacy terminals encode modified F3;
+// the Reader only uses this parser when a report is expected or unambiguous.
+func ParseCursorPosition(data []byte) (*InputEvent, int, error) {
+	terminatorIdx, command, err := scanCSI(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if command != 'R' {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	paramStr := string(data[2:terminatorIdx])
+	paramStr = strings.TrimPrefix(paramStr, "?")
+	params := strings.Split(paramStr, ";")
+	if len(params) < 2 || len(params) > 3 {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	row, err1 := strconv.Atoi(params[0])
+	col, err2 := strconv.Atoi(params[1])
+	if err1 != nil || err2 != nil || row < 1 || col < 1 {
+		return nil, 0, ErrInvalidSequence
+	}
+
+	return &InputEvent{
+		Type:      CursorPositionEventType,
+		CursorRow: uint16(row - 1),
+		CursorCol: uint16(col - 1),
+	}, terminatorIdx + 1, nil
+}
+
 // ParseKitty handles the Kitty Keyboard Protocol sequence format.
 // Based on far2l's robust parsing logic and workarounds.
 func ParseKitty(data []byte) (*InputEvent, int, error) {
@@ -287,6 +672,8 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 	paramStr := string(data[2:terminatorIdx])
 
 	var params [2][3]int
+	// Third section (flag 16, "report associated text"): colon-separated codepoints.
+	var text []rune
 	firstCount := 0
 	secondCount := 0
 
@@ -295,13 +682,13 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 		if paramStr[i] == ';' {
 			secondCount = 0
 			firstCount++
-			if firstCount >= 2 {
+			if firstCount >= 3 || (firstCount == 2 && command != 'u') {
 				return nil, 0, ErrInvalidSequence
 			}
 			i++
 		} else if paramStr[i] == ':' {
 			secondCount++
-			if secondCount >= 3 {
+			if secondCount >= 3 && firstCount < 2 {
 				return nil, 0, ErrInvalidSequence
 			}
 			i++
@@ -311,11 +698,21 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 				val = val*10 + int(paramStr[i]-'0')
 				i++
 			}
-			params[firstCount][secondCount] = val
+			if firstCount == 2 {
+				if secondCount != len(text) || !utf8.ValidRune(rune(val)) {
+					return nil, 0, ErrInvalidSequence
+				}
+				text = append(text, rune(val))
+			} else {
+				params[firstCount][secondCount] = val
+			}
 		} else {
 			return nil, 0, ErrInvalidSequence
 		}
 	}
+	if firstCount == 2 && (len(text) == 0 || len(text) != secondCount+1) {
+		return nil, 0, ErrInvalidSequence
+	}
 
 	eventType := params[1][1]
 	modifState := params[1][0]
@@ -335,13 +732,7 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 	}
 
 	if modifState > 0 {
-		modifState--
-		if (modifState & 1) != 0 { event.ControlKeyState |= ShiftPressed }
-		if (modifState & 2) != 0 { event.ControlKeyState |= LeftAltPressed }
-		if (modifState & 4) != 0 { event.ControlKeyState |= LeftCtrlPressed }
-		if (modifState & 8) != 0 { event.ControlKeyState |= LeftCtrlPressed } // Super -> Ctrl (macOS compat)
-		if (modifState & 64) != 0 { event.ControlKeyState |= CapsLockOn }
-		if (modifState & 128) != 0 { event.ControlKeyState |= NumLockOn }
+		event.ControlKeyState |= decodeAnsiModifiers(modifState)
 	}
 
 	baseChar := params[0][2]
@@ -414,7 +805,8 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 		if command == '~' { event.VirtualKeyCode = VK_F11 }
 	case 24:
 		if command == '~' { event.VirtualKeyCode = VK_F12 }
-	case 32: event.VirtualKeyCode = VK_SPACE
+	case 32:
+		if command == 'u' { event.VirtualKeyCode = VK_SPACE }
 	case 57399, 57425: event.VirtualKeyCode = VK_NUMPAD0
 	case 57400, 57424: event.VirtualKeyCode = VK_NUMPAD1
 	case 57401, 57420: event.VirtualKeyCode = VK_NUMPAD2
@@ -431,8 +823,16 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 	case 57412: event.VirtualKeyCode = VK_SUBTRACT
 	case 57413: event.VirtualKeyCode = VK_ADD
 	case 57414: event.VirtualKeyCode = VK_RETURN
-	case 57444: event.VirtualKeyCode = VK_LWIN
-	case 57450: event.VirtualKeyCode = VK_RWIN
+	case 57444: // Left Super
+		event.VirtualKeyCode = VK_LWIN
+		if eventType != 3 { event.ControlKeyState |= SuperPressed }
+	case 57450: // Right Super
+		event.VirtualKeyCode = VK_RWIN
+		if eventType != 3 { event.ControlKeyState |= SuperPressed }
+	case 57445, 57451: // Left/Right Hyper
+		if eventType != 3 { event.ControlKeyState |= HyperPressed }
+	case 57446, 57452: // Left/Right Meta
+		if eventType != 3 { event.ControlKeyState |= MetaPressed }
 	case 57363: event.VirtualKeyCode = VK_APPS
 	case 57448: // Right Ctrl
 		event.VirtualKeyCode = VK_CONTROL
@@ -480,6 +880,11 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 	case 'S': event.VirtualKeyCode = VK_F4
 	}
 
+	if command == '~' && event.VirtualKeyCode == 0 {
+		// Not a key kitty encodes this way; leave it to ParseLegacyCSI.
+		return nil, 0, ErrInvalidSequence
+	}
+
 	uc := params[0][1]
 	if uc == 0 {
 		uc = params[0][0]
@@ -495,6 +900,14 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 		event.Char = 0
 	}
 
+	if len(text) > 0 {
+		event.Text = string(text)
+		// Text without a key (e.g. IME output) still fills Char when it is a single character.
+		if event.Char == 0 && len(text) == 1 && text[0] >= 32 {
+			event.Char = text[0]
+		}
+	}
+
 	if event.Char > 0 && event.VirtualKeyCode == 0 {
 		event.VirtualKeyCode = VK_UNASSIGNED
 	}
@@ -503,7 +916,9 @@ func ParseKitty(data []byte) (*InputEvent, int, error) {
 		event.Char = unicode.ToUpper(event.Char)
 	}
 
+	// Event types: 1 = press (also when omitted), 2 = repeat, 3 = release.
 	event.KeyDown = (eventType != 3)
+	event.IsRepeat = (eventType == 2)
 	event.RepeatCount = 1
 
 	if (event.ControlKeyState & LeftAltPressed) != 0 || (event.ControlKeyState & RightAltPressed) != 0 {
@@ -2,6 +2,7 @@ package vtinput
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -21,6 +22,8 @@ func TestScanCSI(t *testing.T) {
 		{"Incomplete", []byte("\x1b[1;5"), 0, 0, ErrIncomplete},
 		{"Invalid Start", []byte("ABC"), 0, 0, ErrInvalidSequence},
 		{"Invalid Middle", []byte("\x1b[1\x07A"), 0, 0, ErrInvalidSequence},
+		{"ANSI DECRPM", []byte("\x1b[4;1$y"), 6, 'y', nil},
+		{"DECRPM Intermediate", []byte("\x1b[?2004;1$y"), 10, 'y', nil},
 	}
 
 	for _, tt := range tests {
@@ -75,6 +78,8 @@ func TestParseLegacyCSI(t *testing.T) {
 		{"F5", []byte("\x1b[15~"), VK_F5, 0},
 		{"Ctrl+Delete", []byte("\x1b[3;5~"), VK_DELETE, LeftCtrlPressed},
 		{"Shift+Tab", []byte("\x1b[Z"), VK_TAB, ShiftPressed},
+		{"Super+Left", []byte("\x1b[1;9D"), VK_LEFT, SuperPressed},
+		{"Meta+Ctrl+End", []byte("\x1b[1;37F"), VK_END, MetaPressed | LeftCtrlPressed},
 	}
 
 	for _, tt := range tests {
@@ -95,6 +100,40 @@ func TestParseLegacySS3(t *testing.T) {
 	}
 }
 
+func TestParseLegacySS3_Keypad(t *testing.T) {
+	tests := []struct {
+		input string
+		vk    uint16
+		char  rune
+		mods  uint32
+	}{
+		{"\x1bOp", VK_NUMPAD0, '0', 0},
+		{"\x1bOy", VK_NUMPAD9, '9', 0},
+		{"\x1bOj", VK_MULTIPLY, '*', 0},
+		{"\x1bOk", VK_ADD, '+', 0},
+		{"\x1bOm", VK_SUBTRACT, '-', 0},
+		{"\x1bOn", VK_DECIMAL, '.', 0},
+		{"\x1bOo", VK_DIVIDE, '/', 0},
+		{"\x1bOM", VK_RETURN, '\r', EnhancedKey},
+		{"\x1bO5k", VK_ADD, '+', LeftCtrlPressed},
+		{"\x1bO5P", VK_F1, 0, LeftCtrlPressed},
+		{"\x1bOA", VK_UP, 0, 0},
+	}
+	for _, tt := range tests {
+		event, consumed, err := ParseLegacySS3([]byte(tt.input))
+		if err != nil || consumed != len(tt.input) || event.VirtualKeyCode != tt.vk || event.Char != tt.char || event.ControlKeyState != tt.mods {
+			t.Errorf("%q: got %+v (consumed %d), err %v", tt.input, event, consumed, err)
+		}
+	}
+
+	if _, _, err := ParseLegacySS3([]byte("\x1bO5")); err != ErrIncomplete {
+		t.Errorf("Expected ErrIncomplete for a modifier without final, got %v", err)
+	}
+	if _, _, err := ParseLegacySS3([]byte("\x1bOz")); err != ErrInvalidSequence {
+		t.Errorf("Expected ErrInvalidSequence, got %v", err)
+	}
+}
+
 func TestParseMouseSGR(t *testing.T) {
 	// 1. Left Button Press at 10,20
 	data := []byte("\x1b[<0;10;20M")
@@ -106,7 +145,7 @@ func TestParseMouseSGR(t *testing.T) {
 	// 2. Mouse Wheel Up (Pb=64)
 	dataWheel := []byte("\x1b[<64;10;20M")
 	event, _, err = ParseMouseSGR(dataWheel)
-	if err != nil || event.WheelDirection != 1 {
+	if err != nil || event.WheelDirection != 1 || event.MouseEventFlags != MouseWheeled {
 		t.Errorf("failed to parse Mouse Wheel Up: got %+v, err %v", event, err)
 	}
 
@@ -131,6 +170,31 @@ func TestParseMouseSGR(t *testing.T) {
 		t.Errorf("failed to parse Mouse Move: got %+v", event)
 	}
 }
+
+func TestParseMouseSGR_ExtendedButtons(t *testing.T) {
+	tests := []struct {
+		pb        int
+		buttons   uint32
+		flags     uint32
+		direction int
+	}{
+		{65, 0, MouseWheeled, -1},
+		{66, 0, MouseHWheeled, -1},
+		{67, 0, MouseHWheeled, 1},
+		{128, FromLeft3rdButtonPressed, 0, 0},
+		{129, FromLeft4thButtonPressed, 0, 0},
+		{130, FromLeft5thButtonPressed, 0, 0},
+		{131, FromLeft6thButtonPressed, 0, 0},
+		{128 + 32, FromLeft3rdButtonPressed, MouseMoved, 0},
+	}
+	for _, tt := range tests {
+		data := []byte(fmt.Sprintf("\x1b[<%d;1;1M", tt.pb))
+		event, _, err := ParseMouseSGR(data)
+		if err != nil || event.ButtonState != tt.buttons || event.MouseEventFlags != tt.flags || event.WheelDirection != tt.direction {
+			t.Errorf("Pb=%d: got %+v, err %v", tt.pb, event, err)
+		}
+	}
+}
 func TestParseKitty(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -203,6 +267,43 @@ func TestParseKitty(t *testing.T) {
 				RepeatCount:     1,
 			},
 		},
+		{
+			name: "Key Repeat 'a'",
+			data: []byte("\x1b[97;1:2u"),
+			want: &InputEvent{
+				Type:           KeyEventType,
+				VirtualKeyCode: VK_A,
+				Char:           'a',
+				UnshiftedChar:  'a',
+				KeyDown:        true,
+				IsRepeat:       true,
+				RepeatCount:    1,
+			},
+		},
+		{
+			name: "Key Repeat Insert (Ctrl)",
+			data: []byte("\x1b[2;5:2~"),
+			want: &InputEvent{
+				Type:            KeyEventType,
+				VirtualKeyCode:  VK_INSERT,
+				KeyDown:         true,
+				IsRepeat:        true,
+				ControlKeyState: LeftCtrlPressed,
+				RepeatCount:     1,
+			},
+		},
+		{
+			name: "Explicit Press 'a'",
+			data: []byte("\x1b[97;1:1u"),
+			want: &InputEvent{
+				Type:           KeyEventType,
+				VirtualKeyCode: VK_A,
+				Char:           'a',
+				UnshiftedChar:  'a',
+				KeyDown:        true,
+				RepeatCount:    1,
+			},
+		},
 		{
 			name: "Functional: Escape",
 			data: []byte("\x1b[27u"),
@@ -294,6 +395,91 @@ func TestParseKitty(t *testing.T) {
 				RepeatCount:     1,
 			},
 		},
+		{
+			name: "Super+a",
+			data: []byte("\x1b[97;9u"),
+			want: &InputEvent{
+				Type:            KeyEventType,
+				VirtualKeyCode:  VK_A,
+				Char:            'a',
+				UnshiftedChar:   'a',
+				KeyDown:         true,
+				ControlKeyState: SuperPressed,
+				RepeatCount:     1,
+			},
+		},
+		{
+			name: "Hyper+Meta+a",
+			data: []byte("\x1b[97;49u"),
+			want: &InputEvent{
+				Type:            KeyEventType,
+				VirtualKeyCode:  VK_A,
+				Char:            'a',
+				UnshiftedChar:   'a',
+				KeyDown:         true,
+				ControlKeyState: HyperPressed | MetaPressed,
+				RepeatCount:     1,
+			},
+		},
+		{
+			name: "Left Super",
+			data: []byte("\x1b[57444;9u"),
+			want: &InputEvent{
+				Type:            KeyEventType,
+				VirtualKeyCode:  VK_LWIN,
+				KeyDown:         true,
+				ControlKeyState: SuperPressed,
+				RepeatCount:     1,
+			},
+		},
+		{
+			name: "Associated Text: Shift+a",
+			data: []byte("\x1b[97;2;65u"),
+			want: &InputEvent{
+				Type:            KeyEventType,
+				VirtualKeyCode:  VK_A,
+				Char:            'a',
+				UnshiftedChar:   'a',
+				Text:            "A",
+				KeyDown:         true,
+				ControlKeyState: ShiftPressed,
+				RepeatCount:     1,
+			},
+		},
+		{
+			name: "Associated Text: IME character",
+			data: []byte("\x1b[0;;20320u"),
+			want: &InputEvent{
+				Type:           KeyEventType,
+				VirtualKeyCode: VK_UNASSIGNED,
+				Char:           '你',
+				Text:           "你",
+				KeyDown:        true,
+				RepeatCount:    1,
+			},
+		},
+		{
+			name: "Associated Text: ZWJ emoji sequence",
+			data: []byte("\x1b[0;;128104:8205:128105u"),
+			want: &InputEvent{
+				Type:        KeyEventType,
+				Text:        "👨\u200d👩",
+				KeyDown:     true,
+				RepeatCount: 1,
+			},
+		},
+		{
+			name: "Invalid Associated Text (not CSI u)",
+			data: []byte("\x1b[2;1;97~"),
+			want: nil,
+			err:  ErrInvalidSequence,
+		},
+		{
+			name: "Invalid Associated Text (trailing colon)",
+			data: []byte("\x1b[97;1;97:u"),
+			want: nil,
+			err:  ErrInvalidSequence,
+		},
 		{
 			name: "Invalid Kitty Sequence (bad char)",
 			data: []byte("\x1b[97x"),
@@ -507,3 +693,250 @@ func TestReadEvent_Far2lEqualsBug(t *testing.T) {
 	}
 }
 
+
+func TestParseCursorPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		row, col uint16
+		err      error
+	}{
+		{"Plain", []byte("\x1b[12;40R"), 11, 39, nil},
+		{"DEC With Page", []byte("\x1b[?3;7;1R"), 2, 6, nil},
+		{"Missing Column", []byte("\x1b[5R"), 0, 0, ErrInvalidSequence},
+		{"Wrong Terminator", []byte("\x1b[5;5H"), 0, 0, ErrInvalidSequence},
+		{"Incomplete", []byte("\x1b[5;5"), 0, 0, ErrIncomplete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, consumed, err := ParseCursorPosition(tt.data)
+			if err != tt.err {
+				t.Fatalf("got err %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if consumed != len(tt.data) || event.Type != CursorPositionEventType || event.CursorRow != tt.row || event.CursorCol != tt.col {
+				t.Errorf("got %+v (consumed %d), want row %d col %d", event, consumed, tt.row, tt.col)
+			}
+		})
+	}
+}
+
+func TestParseMouseX10(t *testing.T) {
+	x10 := func(b, x, y int) []byte { return []byte{0x1B, '[', 'M', byte(32 + b), byte(32 + x), byte(32 + y)} }
+
+	// 1. Left Button Press at 10,20
+	event, consumed, err := ParseMouseX10(x10(0, 10, 20), false)
+	if err != nil || consumed != 6 || event.MouseX != 9 || event.MouseY != 19 || event.ButtonState != FromLeft1stButtonPressed || !event.KeyDown {
+		t.Errorf("failed to parse X10 press: got %+v, err %v", event, err)
+	}
+
+	// 2. Release (button code 3) beyond column 95, i.e. a raw byte >= 0x80
+	event, _, err = ParseMouseX10(x10(3, 200, 5), false)
+	if err != nil || event.MouseX != 199 || event.ButtonState != 0 || event.KeyDown {
+		t.Errorf("failed to parse X10 release: got %+v, err %v", event, err)
+	}
+
+	// 3. Ctrl + Right Button drag (2 + 16 + 32)
+	event, _, err = ParseMouseX10(x10(50, 1, 1), false)
+	if err != nil || event.ButtonState != RightmostButtonPressed || (event.MouseEventFlags&MouseMoved) == 0 || (event.ControlKeyState&LeftCtrlPressed) == 0 {
+		t.Errorf("failed to parse X10 drag: got %+v, err %v", event, err)
+	}
+
+	// 4. Extended button press: button 11 (6th) is 128 + 3, not a release
+	event, _, err = ParseMouseX10(x10(131, 1, 1), false)
+	if err != nil || event.ButtonState != FromLeft6thButtonPressed || !event.KeyDown {
+		t.Errorf("failed to parse X10 6th button press: got %+v, err %v", event, err)
+	}
+
+	// 5. UTF-8 coordinates (1005): column 300 does not fit in a byte
+	data := append([]byte("\x1b[M "), string(rune(300+32))...)
+	data = append(data, '!')
+	event, consumed, err = ParseMouseX10(data, true)
+	if err != nil || consumed != len(data) || event.MouseX != 299 || event.MouseY != 0 {
+		t.Errorf("failed to parse UTF-8 mouse: got %+v (consumed %d), err %v", event, consumed, err)
+	}
+
+	// 6. Positions beyond the limit arrive as NUL and are reported at the limit
+	event, _, err = ParseMouseX10([]byte("\x1b[M \x00\x00"), false)
+	if err != nil || event.MouseX != 222 || event.MouseY != 222 {
+		t.Errorf("failed to clamp X10 position: got %+v, err %v", event, err)
+	}
+	event, _, err = ParseMouseX10([]byte("\x1b[M \x00!"), true)
+	if err != nil || event.MouseX != 2014 || event.MouseY != 0 {
+		t.Errorf("failed to clamp UTF-8 position: got %+v, err %v", event, err)
+	}
+	if _, _, err := ParseMouseX10([]byte("\x1b[M\x1f!!"), false); err != ErrInvalidSequence {
+		t.Errorf("Expected ErrInvalidSequence for button byte below 32, got %v", err)
+	}
+
+	// 7. Incomplete report
+	if _, _, err := ParseMouseX10([]byte("\x1b[M !"), false); err != ErrIncomplete {
+		t.Errorf("Expected ErrIncomplete, got %v", err)
+	}
+	if _, _, err := ParseMouseX10(data[:len(data)-2], true); err != ErrIncomplete {
+		t.Errorf("Expected ErrIncomplete for split UTF-8 coordinate, got %v", err)
+	}
+}
+
+func TestParseMouseURXVT(t *testing.T) {
+	event, consumed, err := ParseMouseURXVT([]byte("\x1b[32;300;20M"))
+	if err != nil || consumed != 12 || event.MouseX != 299 || event.MouseY != 19 || event.ButtonState != FromLeft1stButtonPressed || !event.KeyDown {
+		t.Errorf("failed to parse urxvt press: got %+v, err %v", event, err)
+	}
+
+	event, _, err = ParseMouseURXVT([]byte("\x1b[35;1;1M"))
+	if err != nil || event.KeyDown || event.ButtonState != 0 {
+		t.Errorf("failed to parse urxvt release: got %+v, err %v", event, err)
+	}
+
+	for _, input := range []string{"\x1b[32;1M", "\x1b[32;-5;1M", "\x1b[32;1;0M", "\x1b[31;1;1M", "\x1b[32;70000;1M"} {
+		if _, _, err := ParseMouseURXVT([]byte(input)); err != ErrInvalidSequence {
+			t.Errorf("%q: expected ErrInvalidSequence, got %v", input, err)
+		}
+	}
+}
+
+func TestParseModifyOtherKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		vk    uint16
+		char  rune
+		mods  uint32
+	}{
+		{"\x1b[27;5;13~", VK_RETURN, 0, LeftCtrlPressed},
+		{"\x1b[27;6;9~", VK_TAB, 0, LeftCtrlPressed | ShiftPressed},
+		{"\x1b[27;5;49~", 0, '1', LeftCtrlPressed},
+		{"\x1b[27;6;33~", 0, '!', LeftCtrlPressed | ShiftPressed},
+		{"\x1b[27;3;1103~", 0, 'я', LeftAltPressed},
+	}
+	for _, tt := range tests {
+		event, consumed, err := ParseModifyOtherKeys([]byte(tt.input))
+		if err != nil || consumed != len(tt.input) || event.VirtualKeyCode != tt.vk || event.Char != tt.char || event.ControlKeyState != tt.mods || !event.KeyDown {
+			t.Errorf("%q: got %+v (consumed %d), err %v", tt.input, event, consumed, err)
+		}
+	}
+
+	if _, _, err := ParseModifyOtherKeys([]byte("\x1b[2;5~")); err != ErrInvalidSequence {
+		t.Errorf("Expected ErrInvalidSequence for Ctrl+Insert, got %v", err)
+	}
+
+	e, err := NewReader(bytes.NewReader([]byte("\x1b[27;5;13~"))).ReadEvent()
+	if err != nil || e.VirtualKeyCode != VK_RETURN || e.ControlKeyState != LeftCtrlPressed {
+		t.Errorf("Expected Ctrl+Enter from the reader, got (%+v, %v)", e, err)
+	}
+	e, err = NewReader(bytes.NewReader([]byte("\x1b[27;6;33~"))).ReadEvent()
+	if err != nil || e.VirtualKeyCode != VK_1 || e.UnshiftedChar != '1' || e.Char != '!' {
+		t.Errorf("Expected the layout to fill in Ctrl+Shift+1, got (%+v, %v)", e, err)
+	}
+}
+
+func TestParseRxvt(t *testing.T) {
+	tests := []struct {
+		input string
+		vk    uint16
+		mods  uint32
+	}{
+		{"\x1b[2$", VK_INSERT, ShiftPressed},
+		{"\x1b[5^", VK_PRIOR, LeftCtrlPressed},
+		{"\x1b[11@", VK_F1, LeftCtrlPressed | ShiftPressed},
+		{"\x1b[7$", VK_HOME, ShiftPressed},
+		{"\x1b[a", VK_UP, ShiftPressed},
+		{"\x1b[d", VK_LEFT, ShiftPressed},
+		{"\x1bOa", VK_UP, LeftCtrlPressed},
+		{"\x1bOc", VK_RIGHT, LeftCtrlPressed},
+	}
+	for _, tt := range tests {
+		event, consumed, err := ParseRxvt([]byte(tt.input))
+		if err != nil || consumed != len(tt.input) || event.VirtualKeyCode != tt.vk || event.ControlKeyState != tt.mods {
+			t.Errorf("%q: got %+v (consumed %d), err %v", tt.input, event, consumed, err)
+		}
+	}
+
+	for _, input := range []string{"\x1b[99$", "\x1b[1a", "\x1bOz"} {
+		if _, _, err := ParseRxvt([]byte(input)); err != ErrInvalidSequence {
+			t.Errorf("%q: expected ErrInvalidSequence, got %v", input, err)
+		}
+	}
+
+	// The '$' final ends the sequence only after a run of digits.
+	if idx, cmd, err := scanCSIOrRxvt([]byte("\x1b[2$a")); idx != 3 || cmd != '$' || err != nil {
+		t.Errorf("scanCSIOrRxvt(rxvt Shift) = (%d, %c, %v)", idx, cmd, err)
+	}
+	if idx, cmd, err := scanCSIOrRxvt([]byte("\x1b[4;1$y")); idx != 6 || cmd != 'y' || err != nil {
+		t.Errorf("scanCSIOrRxvt(ANSI DECRPM) = (%d, %c, %v)", idx, cmd, err)
+	}
+
+	// Through the reader, followed by plain keys; an ANSI DECRPM reply is
+	// not mistaken for rxvt keys.
+	r := NewReader(bytes.NewReader([]byte("\x1b[5^\x1bOb\x1b[2$y\x1b[4;1$yz")))
+	for _, want := range []uint16{VK_PRIOR, VK_DOWN, VK_INSERT, VK_Y, VK_Z} {
+		e, err := r.ReadEvent()
+		if err != nil || e.VirtualKeyCode != want {
+			t.Errorf("Expected VK 0x%X, got (%+v, %v)", want, e, err)
+		}
+	}
+}
+
+func TestLegacyDialects(t *testing.T) {
+	type key struct {
+		seq  string
+		vk   uint16
+		mods uint32
+	}
+	corpus := map[string][]key{
+		"linux": {
+			{"\x1b[[A", VK_F1, 0},
+			{"\x1b[[B", VK_F2, 0},
+			{"\x1b[[C", VK_F3, 0},
+			{"\x1b[[D", VK_F4, 0},
+			{"\x1b[[E", VK_F5, 0},
+			{"\x1b[17~", VK_F6, 0},
+			{"\x1b[1~", VK_HOME, 0},
+			{"\x1b[4~", VK_END, 0},
+		},
+		"vt220": {
+			{"\x1b[11~", VK_F1, 0},
+			{"\x1b[24~", VK_F12, 0},
+			{"\x1b[25~", VK_F13, 0},
+			{"\x1b[26~", VK_F14, 0},
+			{"\x1b[28~", VK_HELP, 0},
+			{"\x1b[29~", VK_EXECUTE, 0},
+			{"\x1b[31~", VK_F17, 0},
+			{"\x1b[32~", VK_F18, 0},
+			{"\x1b[33~", VK_F19, 0},
+			{"\x1b[34~", VK_F20, 0},
+		},
+		"xterm": {
+			{"\x1bOP", VK_F1, 0},
+			{"\x1b[1;5P", VK_F1, LeftCtrlPressed},
+			{"\x1b[15;2~", VK_F5, ShiftPressed},
+			{"\x1b[34;5~", VK_F20, LeftCtrlPressed},
+			{"\x1b[1;3A", VK_UP, LeftAltPressed},
+		},
+		"rxvt": {
+			{"\x1b[2$", VK_INSERT, ShiftPressed},
+			{"\x1b[5^", VK_PRIOR, LeftCtrlPressed},
+			{"\x1b[11@", VK_F1, LeftCtrlPressed | ShiftPressed},
+			{"\x1b[a", VK_UP, ShiftPressed},
+			{"\x1bOd", VK_LEFT, LeftCtrlPressed},
+		},
+	}
+
+	for term, keys := range corpus {
+		for _, k := range keys {
+			// Each key is followed by 'z' to check that it was fully consumed.
+			r := NewReader(bytes.NewReader([]byte(k.seq + "z")))
+			e, err := r.ReadEvent()
+			if err != nil || e.VirtualKeyCode != k.vk || e.ControlKeyState&^EnhancedKey != k.mods {
+				t.Errorf("%s %q: got (%+v, %v), want VK 0x%X mods 0x%X", term, k.seq, e, err, k.vk, k.mods)
+				continue
+			}
+			if e, err := r.ReadEvent(); err != nil || e.Char != 'z' {
+				t.Errorf("%s %q: expected 'z' next, got (%+v, %v)", term, k.seq, e, err)
+			}
+		}
+	}
+}
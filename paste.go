@@ -0,0 +1,184 @@
+package vtinput
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+var (
+	pasteEnd   = []byte("\x1b[201~")
+	pasteEndC1 = []byte("\x9b201~")
+)
+
+// pasteEnds returns the end markers that terminate a paste.
+func (r *Reader) pasteEnds() [][]byte {
+	if r.opts.C1Controls {
+		return [][]byte{pasteEnd, pasteEndC1}
+	}
+	return [][]byte{pasteEnd}
+}
+
+// indexPasteEnd returns the position and length of the first end marker in
+// the buffer, or -1. The 8-bit marker only counts where 0x9B cannot be the
+// continuation byte of a UTF-8 character, as in "Û201~".
+func (r *Reader) indexPasteEnd() (idx, markerLen int) {
+	idx = -1
+	for _, marker := range r.pasteEnds() {
+		for from := 0; from < len(r.buf); {
+			i := bytes.Index(r.buf[from:], marker)
+			if i < 0 {
+				break
+			}
+			i += from
+			if marker[0] == c1CSI && r.midRune(i) {
+				from = i + 1
+				continue
+			}
+			if idx < 0 || i < idx {
+				idx, markerLen = i, len(marker)
+			}
+			break
+		}
+	}
+	return idx, markerLen
+}
+
+// midRune reports whether the bytes before r.buf[i], including those already
+// collected, start a UTF-8 character that r.buf[i] would continue.
+func (r *Reader) midRune(i int) bool {
+	for back := 1; back < utf8.UTFMax; back++ {
+		var b byte
+		switch {
+		case i >= back:
+			b = r.buf[i-back]
+		case len(r.paste) >= back-i:
+			b = r.paste[len(r.paste)-(back-i)]
+		default:
+			return false
+		}
+		switch {
+		case b < 0x80:
+			return false
+		case b >= 0xF0:
+			return back <= 3
+		case b >= 0xE0:
+			return back <= 2
+		case b >= 0xC0:
+			return back <= 1
+		}
+	}
+	return false
+}
+
+// readPaste moves buffered bytes into the paste being collected and returns
+// a paste event once one is due, or nil if more input is needed.
+func (r *Reader) readPaste() *InputEvent {
+	idx, markerLen := r.indexPasteEnd()
+	switch {
+	case idx >= 0:
+		r.appendPaste(r.buf[:idx])
+		r.buf = r.buf[idx:]
+		if r.opts.StreamPaste && len(r.paste) > r.opts.MaxPasteSize {
+			// Leave the end marker buffered until the rest has been emitted.
+			return r.pasteEvent(r.opts.MaxPasteSize, true)
+		}
+		r.buf = r.buf[markerLen:]
+		r.inPaste = false
+		return r.pasteEvent(len(r.paste), false)
+
+	case r.readErr != nil:
+		// The paste can never be terminated; deliver what we have.
+		r.appendPaste(r.buf)
+		r.buf = r.buf[:0]
+		r.inPaste = false
+		return r.pasteEvent(len(r.paste), false)
+	}
+
+	// Hold back a tail that may be the beginning of a split end marker.
+	keep := 0
+	for _, marker := range r.pasteEnds() {
+		for n := min(len(r.buf), len(marker)-1); n > keep; n-- {
+			if bytes.HasPrefix(marker, r.buf[len(r.buf)-n:]) {
+				keep = n
+				break
+			}
+		}
+	}
+	r.appendPaste(r.buf[:len(r.buf)-keep])
+	r.buf = r.buf[len(r.buf)-keep:]
+
+	if !r.opts.StreamPaste {
+		return nil
+	}
+	n := min(len(r.paste), r.opts.MaxPasteSize)
+	if n == len(r.paste) {
+		// Don't emit a character whose remaining bytes are still in flight.
+		n = fullRunesPrefix(r.paste)
+	}
+	if n == 0 {
+		return nil
+	}
+	return r.pasteEvent(n, true)
+}
+
+// appendPaste adds p to the collected paste. Unless the paste is streamed,
+// text past MaxPasteSize is discarded, cut at a character boundary.
+func (r *Reader) appendPaste(p []byte) {
+	if !r.opts.StreamPaste && len(r.paste)+len(p) > r.opts.MaxPasteSize {
+		if r.pasteFull {
+			return
+		}
+		p = p[:runeStartBefore(p, r.opts.MaxPasteSize-len(r.paste))]
+		r.pasteFull = true
+	}
+	r.paste = append(r.paste, p...)
+}
+
+// pasteEvent emits the first n bytes of the collected paste, cut back to a
+// character boundary when more text follows.
+func (r *Reader) pasteEvent(n int, partial bool) *InputEvent {
+	if n < len(r.paste) {
+		if cut := runeStartBefore(r.paste, n); cut > 0 {
+			n = cut
+		}
+	}
+	event := &InputEvent{
+		Type:           PasteEventType,
+		PasteText:      string(r.paste[:n]),
+		PasteCollected: true,
+		PastePartial:   partial,
+		PasteTruncated: r.pasteFull && !partial,
+	}
+	r.paste = r.paste[:copy(r.paste, r.paste[n:])]
+	if !partial {
+		r.pasteFull = false
+	}
+	return event
+}
+
+// runeStartBefore returns the largest index <= n that starts a UTF-8
+// character in p, looking back no further than one character.
+func runeStartBefore(p []byte, n int) int {
+	if n >= len(p) {
+		return len(p)
+	}
+	for cut := n; cut > 0 && cut > n-utf8.UTFMax; cut-- {
+		if utf8.RuneStart(p[cut]) {
+			return cut
+		}
+	}
+	return n
+}
+
+// fullRunesPrefix returns the length of p without a trailing incomplete
+// UTF-8 character.
+func fullRunesPrefix(p []byte) int {
+	start := len(p) - 1
+	for start > 0 && start > len(p)-utf8.UTFMax && !utf8.RuneStart(p[start]) {
+		start--
+	}
+	if start >= 0 && !utf8.FullRune(p[start:]) {
+		return start
+	}
+	return len(p)
+}
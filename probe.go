@@ -0,0 +1,204 @@
+package vtinput
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Queries sent by Probe. DA1 goes last: every terminal answers it, and
+// replies arrive in order, so its answer means the others are in.
+const (
+	seqQueryKitty = "\x1b[?u"
+	seqQueryModes = "\x1b[?9001$p\x1b[?1004$p\x1b[?2004$p\x1b[?1006$p\x1b[?1016$p"
+	seqQueryDA1   = "\x1b[c"
+)
+
+// Capabilities describes what a terminal reported about itself to Probe.
+type Capabilities struct {
+	// Responded is set when the terminal answered the DA1 query in time.
+	// If it is false, nothing is known and the other fields are unreliable.
+	Responded bool
+
+	// KittyKeyboard is set if the terminal answered the kitty flags query;
+	// KittyFlags holds the progressive enhancement flags active at the time.
+	KittyKeyboard bool
+	KittyFlags    int
+
+	// Modes recognized by the terminal according to DECRQM.
+	Win32InputMode bool // ?9001
+	FocusEvents    bool // ?1004
+	BracketedPaste bool // ?2004
+	SGRMouse       bool // ?1006
+	SGRPixelMouse  bool // ?1016
+
+	// Cell size in pixels from the CSI 16t reply, 0 if not reported.
+	CellWidth  int
+	CellHeight int
+
+	// DeviceAttributes lists the parameters of the DA1 reply.
+	DeviceAttributes []int
+}
+
+// Protocols returns the set of protocols the terminal claims to support,
+// suitable for EnableProtocols. MousePixels is never included even when
+// SGRPixelMouse is set: it changes what mouse reports mean, so the caller
+// must opt in and set ReaderOptions.MousePixels to match.
+func (c Capabilities) Protocols() Protocol {
+	var p Protocol
+	if c.KittyKeyboard {
+		p |= KittyKeyboard
+	}
+	if c.Win32InputMode {
+		p |= Win32InputMode
+	}
+	if c.SGRMouse {
+		p |= MouseSupport
+	}
+	if c.FocusEvents && c.BracketedPaste {
+		p |= FocusAndPaste
+	}
+	return p
+}
+
+// Probe queries the terminal for keyboard protocol support: the kitty flags
+// (CSI ? u), DECRQM for modes 9001, 1004, 2004, 1006 and 1016, the cell size
+// (CSI 16t), and a DA1 sentinel.
+// Queries are written to w (usually os.Stdout) and replies are read through r,
+// so the terminal must already be in raw mode. Probe waits until the DA1
+// answer arrives or timeout passes; in the latter case Responded is false.
+// Input events that arrive meanwhile are kept and returned by later reads.
+// Probe must not run while another goroutine reads from r, including Events.
+func (r *Reader) Probe(w io.Writer, timeout time.Duration) (*Capabilities, error) {
+	caps := &Capabilities{}
+	if _, err := io.WriteString(w, seqQueryKitty+seqQueryModes+seqRequestCellSize+seqQueryDA1); err != nil {
+		return caps, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	r.probe = caps
+	defer func() { r.probe = nil }()
+
+	for {
+		event, err := r.readEvent(ctx)
+		if err == context.DeadlineExceeded {
+			return caps, nil
+		}
+		if err != nil {
+			return caps, err
+		}
+		if event == nil {
+			return caps, nil
+		}
+		r.queued = append(r.queued, event)
+	}
+}
+
+// terminalReply is an answer to one of the queries sent by Probe.
+type terminalReply struct {
+	command byte  // 'u' kitty flags, 'y' DECRPM, 'c' DA1, 't' cell size
+	params  []int // numeric parameters in order
+	ansi    bool  // DECRPM for an ANSI mode (no '?'), which Probe never asks about
+}
+
+// parseTerminalReply recognizes a complete CSI sequence that is a reply to a
+// Probe query: CSI ? flags u, CSI ? mode ; state $ y, CSI ? attrs c or
+// CSI 6 ; height ; width t. DECRPM for an ANSI mode (CSI mode ; state $ y)
+// is recognized too, so that it stays out of the event stream.
+func parseTerminalReply(seq []byte) (terminalReply, bool) {
+	if len(seq) < 4 {
+		return terminalReply{}, false
+	}
+	command := seq[len(seq)-1]
+	if command == 't' {
+		return parseCellSizeReply(seq)
+	}
+	body := string(seq[3 : len(seq)-1])
+	ansi := seq[2] != '?'
+	if ansi {
+		if command != 'y' {
+			return terminalReply{}, false
+		}
+		body = string(seq[2 : len(seq)-1])
+	}
+
+	switch command {
+	case 'u', 'c':
+	case 'y':
+		var ok bool
+		if body, ok = strings.CutSuffix(body, "$"); !ok {
+			return terminalReply{}, false
+		}
+	default:
+		return terminalReply{}, false
+	}
+
+	reply := terminalReply{command: command, ansi: ansi}
+	for _, s := range strings.Split(body, ";") {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return terminalReply{}, false
+		}
+		reply.params = append(reply.params, v)
+	}
+	if command == 'y' && len(reply.params) != 2 {
+		return terminalReply{}, false
+	}
+	return reply, true
+}
+
+// parseCellSizeReply recognizes CSI 6 ; height ; width t.
+func parseCellSizeReply(seq []byte) (terminalReply, bool) {
+	params := strings.Split(string(seq[2:len(seq)-1]), ";")
+	if len(params) != 3 || params[0] != "6" {
+		return terminalReply{}, false
+	}
+	reply := terminalReply{command: 't', params: []int{6, 0, 0}}
+	for i := 1; i < 3; i++ {
+		v, err := strconv.Atoi(params[i])
+		if err != nil {
+			return terminalReply{}, false
+		}
+		reply.params[i] = v
+	}
+	return reply, true
+}
+
+// record folds a reply into c and reports whether it was the DA1 sentinel.
+func (c *Capabilities) record(reply terminalReply) bool {
+	switch reply.command {
+	case 'u':
+		c.KittyKeyboard = true
+		c.KittyFlags = reply.params[0]
+	case 'y':
+		if reply.ansi {
+			break
+		}
+		// 0: not recognized, 1/2: set/reset, 3/4: permanently set/reset.
+		state := reply.params[1]
+		supported := state >= 1 && state <= 3
+		switch reply.params[0] {
+		case 9001:
+			c.Win32InputMode = supported
+		case 1004:
+			c.FocusEvents = supported
+		case 2004:
+			c.BracketedPaste = supported
+		case 1006:
+			c.SGRMouse = supported
+		case 1016:
+			c.SGRPixelMouse = supported
+		}
+	case 't':
+		c.CellHeight, c.CellWidth = reply.params[1], reply.params[2]
+	case 'c':
+		c.Responded = true
+		c.DeviceAttributes = reply.params
+		return true
+	}
+	return false
+}
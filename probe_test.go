@@ -0,0 +1,74 @@
+package vtinput
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProbe(t *testing.T) {
+	// A key typed during probing, the replies, then a key typed after.
+	replies := "x" +
+		"\x1b[?7u" +
+		"\x1b[?9001;2$y\x1b[?1004;0$y\x1b[?2004;1$y\x1b[?1006;4$y\x1b[?1016;1$y" +
+		"\x1b[6;18;9t" +
+		"\x1b[?62;22c" +
+		"y"
+	r := NewReader(bytes.NewReader([]byte(replies)))
+	defer r.Close()
+
+	var queries bytes.Buffer
+	caps, err := r.Probe(&queries, time.Second)
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if queries.String() != seqQueryKitty+seqQueryModes+seqRequestCellSize+seqQueryDA1 {
+		t.Errorf("Unexpected queries %q", queries.String())
+	}
+
+	want := &Capabilities{
+		Responded:        true,
+		KittyKeyboard:    true,
+		KittyFlags:       7,
+		Win32InputMode:   true,
+		BracketedPaste:   true,
+		SGRPixelMouse:    true,
+		CellWidth:        9,
+		CellHeight:       18,
+		DeviceAttributes: []int{62, 22},
+	}
+	if !reflect.DeepEqual(caps, want) {
+		t.Errorf("got %+v, want %+v", caps, want)
+	}
+	if p := caps.Protocols(); p != KittyKeyboard|Win32InputMode {
+		t.Errorf("Protocols() = %b", p)
+	}
+
+	for _, c := range "xy" {
+		e, err := r.ReadEvent()
+		if err != nil || e.Char != c {
+			t.Errorf("Expected %q to survive probing, got (%+v, %v)", c, e, err)
+		}
+	}
+}
+
+func TestProbe_Timeout(t *testing.T) {
+	pr, _ := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	caps, err := r.Probe(io.Discard, 30*time.Millisecond)
+	if err != nil || caps.Responded || caps.Protocols() != 0 {
+		t.Errorf("Expected silent terminal to yield no capabilities, got (%+v, %v)", caps, err)
+	}
+}
+
+func TestReadEvent_UnsolicitedReplies(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("\x1b[?1u\x1b[?2004;1$y\x1b[?1;2ca")))
+	e, err := r.ReadEvent()
+	if err != nil || e.Char != 'a' {
+		t.Errorf("Expected terminal replies to be skipped, got (%+v, %v)", e, err)
+	}
+}
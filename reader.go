@@ -1,20 +1,304 @@
 package vtinput
 
 import (
+	"bytes"
+	"context"
 	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 )
 
+const (
+	defaultEscTimeout    = 100 * time.Millisecond
+	defaultMaxEscTimeout = time.Second
+	defaultMaxPasteSize  = 16 << 20
+	defaultClickInterval = 500 * time.Millisecond
+	defaultKeyUpTimeout  = 150 * time.Millisecond
+
+	// readChunkSize is the size of a single read from the underlying input.
+	readChunkSize = 4096
+
+	// 8-bit C1 forms of CSI (ESC [) and SS3 (ESC O).
+	c1CSI = 0x9B
+	c1SS3 = 0x8F
+)
+
+// EscPolicy controls how the Reader decides that a lone ESC byte is the
+// Escape key rather than the start of a sequence that has not fully arrived.
+type EscPolicy int32
+
+const (
+	// EscFixed waits ReaderOptions.EscTimeout for the rest of a sequence.
+	EscFixed EscPolicy = iota
+	// EscAdaptive starts from EscTimeout and stretches it towards the measured
+	// delay between sequence bytes, for slow links where sequences arrive split.
+	EscAdaptive
+	// EscNever waits for the rest of a sequence indefinitely. Use it once kitty
+	// or win32 input mode is known to be active: those encode the Escape key
+	// itself as a sequence, so a bare ESC is always a prefix.
+	EscNever
+)
+
+// ReaderOptions configures a Reader created with NewReaderWithOptions.
+// The zero value matches NewReader.
+type ReaderOptions struct {
+	// EscTimeout is how long a lone ESC waits for the rest of a sequence.
+	// Zero means 100ms.
+	EscTimeout time.Duration
+	// EscPolicy selects how EscTimeout is applied.
+	EscPolicy EscPolicy
+	// MaxEscTimeout caps the timeout EscAdaptive may grow to. Zero means 1s.
+	MaxEscTimeout time.Duration
+
+	// CollectPaste delivers bracketed paste content verbatim in the PasteText
+	// field of a PasteEventType event, instead of start/end markers around
+	// individually parsed keys.
+	CollectPaste bool
+	// MaxPasteSize limits the text carried by one collected paste event.
+	// Zero means 16 MiB. Without StreamPaste, text beyond it is discarded and
+	// the event is marked PasteTruncated.
+	MaxPasteSize int
+	// StreamPaste emits collected paste text as it arrives, in events of at
+	// most MaxPasteSize bytes marked PastePartial until the paste ends.
+	StreamPaste bool
+
+	// MouseUTF8 decodes classic (X10 style) mouse reports with UTF-8 encoded
+	// coordinates, as sent by terminals in mode 1005.
+	MouseUTF8 bool
+
+	// MousePixels reads SGR mouse reports as pixel positions, for terminals
+	// put into SGR-Pixels mode (Protocol MousePixels).
+	MousePixels bool
+
+	// TrackMouseButtons makes ButtonState of every mouse event hold all
+	// buttons that are down after it, like a Win32 MOUSE_EVENT_RECORD, rather
+	// than just the button in the report. Releases and motion then carry the
+	// buttons still held.
+	TrackMouseButtons bool
+
+	// DetectClicks counts quick successive presses of the same mouse button
+	// in InputEvent.ClickCount and marks the second and later ones with the
+	// DoubleClick flag.
+	DetectClicks bool
+	// ClickInterval is the longest pause between presses of one series.
+	// Zero means 500ms.
+	ClickInterval time.Duration
+	// ClickDistance is how many cells the pointer may move, on either axis,
+	// between presses of one series. Zero means they must hit the same cell.
+	// With MousePixels it counts pixels instead, since cell positions are
+	// unknown until the terminal reports its cell size.
+	ClickDistance int
+
+	// SynthesizeKeyUp follows each legacy key-down (IsLegacy) with a key-up
+	// marked IsSynthetic, once KeyUpTimeout passes without the key repeating
+	// or as soon as another key arrives, so that every protocol yields pairs.
+	SynthesizeKeyUp bool
+	// KeyUpTimeout is how long a legacy key counts as held after its last
+	// key-down. It should exceed the auto-repeat interval. Zero means 150ms.
+	KeyUpTimeout time.Duration
+
+	// Layout fills in VirtualKeyCode, ShiftPressed and UnshiftedChar of legacy
+	// character events. Nil means LayoutUS; an empty Layout turns this off.
+	Layout Layout
+
+	// C1Controls accepts the 8-bit introducers CSI (0x9B) and SS3 (0x8F), as
+	// sent by terminals set up for 8-bit controls. UTF-8 input keeps working:
+	// these bytes never start a valid UTF-8 character.
+	C1Controls bool
+
+	// SuperAsCtrl reports the Super (Win/Cmd) modifier as LeftCtrl, for
+	// applications that want Cmd+C to behave like Ctrl+C on macOS.
+	SuperAsCtrl bool
+}
+
 // Reader wraps an io.Reader (like os.Stdin) and parses input events.
 // It buffers input internally to handle incomplete escape sequences.
 type Reader struct {
 	in       io.Reader
 	buf      []byte
-	dataChan chan byte
+	dataChan chan []byte
 	errChan  chan error
 	done     chan struct{}
 	stopPipe [2]int // Used on Unix for Select unblocking
+
+	opts      ReaderOptions
+	escPolicy atomic.Int32
+	// escLatency is the smoothed delay observed between an incomplete
+	// sequence and its continuation, used by EscAdaptive.
+	escLatency time.Duration
+	// escExpired is when the last ESC wait started that ended in a timeout.
+	// If a sequence tail follows soon after, the timeout was too short.
+	escExpired time.Time
+
+	// cprPending counts DSR 6 queries whose Cursor Position Report is still due.
+	cprPending atomic.Int32
+
+	// cellSize packs the width (high half) and height in pixels from the last
+	// CSI 16t reply, 0 if unknown. CellSize may read it from any goroutine.
+	cellSize atomic.Uint64
+
+	// mouseButtons is the set of buttons held, kept when TrackMouseButtons is on.
+	mouseButtons uint32
+
+	// Last press seen by DetectClicks and the length of its series.
+	clickButton uint32
+	clickTime   time.Time
+	clickX      int
+	clickY      int
+	clickCount  uint16
+
+	// keyUp is the synthetic key-up due for the legacy key pressed at keyUpAt.
+	keyUp   *InputEvent
+	keyUpAt time.Time
+	// deferred is a processed event held back behind a synthetic key-up.
+	deferred *InputEvent
+
+	// probe collects terminal replies while Probe is running.
+	probe *Capabilities
+	// queued holds events that arrived during Probe, returned before new input.
+	queued []*InputEvent
+
+	// c1Intro is set while the buffer starts with an 8-bit introducer that
+	// expandC1 rewrote into its 7-bit form.
+	c1Intro bool
+
+	// inPaste is set between bracketed paste markers when CollectPaste is on.
+	inPaste   bool
+	paste     []byte
+	pasteFull bool // MaxPasteSize was reached; drop the rest
+
+	// readErr is the error that stopped the background goroutine. Once set,
+	// no more data will arrive and it is returned when the buffer runs dry.
+	readErr error
+
+	eventsOnce sync.Once
+	events     chan *InputEvent
+	eventsMu   sync.Mutex
+	eventsErr  error
+}
+
+// NewReader creates a Reader with default options.
+func NewReader(in io.Reader) *Reader {
+	return NewReaderWithOptions(in, ReaderOptions{})
+}
+
+// NewReaderWithOptions creates a Reader configured by opts.
+func NewReaderWithOptions(in io.Reader, opts ReaderOptions) *Reader {
+	if opts.EscTimeout <= 0 {
+		opts.EscTimeout = defaultEscTimeout
+	}
+	if opts.MaxEscTimeout <= 0 {
+		opts.MaxEscTimeout = defaultMaxEscTimeout
+	}
+	if opts.MaxEscTimeout < opts.EscTimeout {
+		opts.MaxEscTimeout = opts.EscTimeout
+	}
+	if opts.MaxPasteSize <= 0 {
+		opts.MaxPasteSize = defaultMaxPasteSize
+	}
+	if opts.ClickInterval <= 0 {
+		opts.ClickInterval = defaultClickInterval
+	}
+	if opts.KeyUpTimeout <= 0 {
+		opts.KeyUpTimeout = defaultKeyUpTimeout
+	}
+	if opts.Layout == nil {
+		opts.Layout = LayoutUS
+	}
+
+	r := &Reader{
+		in:       in,
+		buf:      make([]byte, 0, 128),
+		dataChan: make(chan []byte, 64),
+		errChan:  make(chan error, 1),
+		done:     make(chan struct{}),
+		opts:     opts,
+	}
+	r.escPolicy.Store(int32(opts.EscPolicy))
+	r.start()
+	return r
+}
+
+// SetEscPolicy changes the ESC disambiguation policy, e.g. to EscNever after
+// confirming that kitty or win32 input mode is active. It is safe to call
+// while another goroutine is reading.
+func (r *Reader) SetEscPolicy(p EscPolicy) {
+	r.escPolicy.Store(int32(p))
+}
+
+// escTimer returns a channel that fires when a pending ESC should be
+// reported as the Escape key, or nil if it should wait indefinitely.
+func (r *Reader) escTimer() <-chan time.Time {
+	timeout := r.opts.EscTimeout
+	switch EscPolicy(r.escPolicy.Load()) {
+	case EscNever:
+		return nil
+	case EscAdaptive:
+		if adaptive := 3 * r.escLatency; adaptive > timeout {
+			timeout = min(adaptive, r.opts.MaxEscTimeout)
+		}
+	}
+	return time.After(timeout)
+}
+
+// observeEscLatency folds the delay before a sequence continued into the
+// smoothed estimate used by EscAdaptive.
+func (r *Reader) observeEscLatency(d time.Duration) {
+	if r.escLatency == 0 {
+		r.escLatency = d
+		return
+	}
+	r.escLatency = (3*r.escLatency + d) / 4
+}
+
+// ExpectCursorPosition tells the Reader that a DSR 6 query (CSI 6n) has been
+// sent, so the next CSI 1;<n> R is parsed as a Cursor Position Report rather
+// than a modified F3 key. Call it once per query, before writing it.
+func (r *Reader) ExpectCursorPosition() {
+	r.cprPending.Add(1)
+}
+
+// RequestCursorPosition writes a DSR 6 query to w and marks the reply as
+// expected. The answer arrives as a CursorPositionEventType event.
+func (r *Reader) RequestCursorPosition(w io.Writer) error {
+	r.ExpectCursorPosition()
+	if _, err := io.WriteString(w, seqRequestCursorPosition); err != nil {
+		r.cprPending.Add(-1)
+		return err
+	}
+	return nil
+}
+
+// RequestCellSize writes a CSI 16t query to w. Once the reply has been read,
+// SGR-Pixels mouse events also carry cell coordinates and CellSize reports it.
+func (r *Reader) RequestCellSize(w io.Writer) error {
+	_, err := io.WriteString(w, seqRequestCellSize)
+	return err
+}
+
+// CellSize returns the cell size in pixels reported by the terminal in reply
+// to RequestCellSize or Probe, or zeros if it is not known yet. It is safe to
+// call while another goroutine is reading.
+func (r *Reader) CellSize() (width, height int) {
+	size := r.cellSize.Load()
+	return int(size >> 32), int(uint32(size))
+}
+
+// isCursorReport decides whether a complete CSI ... R sequence is a Cursor
+// Position Report. Modified F3 is always CSI 1;<mod> R, so reports for other
+// rows and the DEC form (CSI ? ...) are unambiguous; CSI 1;<n> R counts as a
+// report only while one is expected.
+func (r *Reader) isCursorReport(seq []byte) bool {
+	params := seq[2 : len(seq)-1]
+	if len(params) > 0 && params[0] == '?' {
+		return true
+	}
+	if !bytes.ContainsRune(params, ';') {
+		return false
+	}
+	return r.cprPending.Load() > 0 || !bytes.HasPrefix(params, []byte("1;"))
 }
 
 // Close stops the background reading goroutine instantly.
@@ -30,17 +314,149 @@ func (r *Reader) Close() {
 
 // ReadEvent reads the next input event.
 func (r *Reader) ReadEvent() (*InputEvent, error) {
+	return r.ReadEventContext(context.Background())
+}
+
+// ReadEventContext reads the next input event, giving up with ctx.Err() when
+// ctx is cancelled or its deadline passes. Unlike Close, cancellation leaves the
+// reader usable: bytes of a partially received sequence stay buffered and the
+// next call continues from there.
+func (r *Reader) ReadEventContext(ctx context.Context) (*InputEvent, error) {
+	if event := r.deferred; event != nil {
+		r.deferred = nil
+		return event, nil
+	}
+
+	var event *InputEvent
+	if len(r.queued) > 0 {
+		event = r.queued[0]
+		r.queued = r.queued[1:]
+	} else {
+		var err error
+		if r.keyUp != nil {
+			event, err = r.readWithKeyUp(ctx)
+			if err == nil && event.IsSynthetic {
+				return event, nil
+			}
+		} else {
+			event, err = r.readEvent(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.applyOptions(event)
+	if r.opts.SynthesizeKeyUp {
+		event = r.pairKeyUp(event)
+	}
+	return event, nil
+}
+
+// applyOptions adjusts a parsed event according to the reader options.
+func (r *Reader) applyOptions(event *InputEvent) {
+	if event.IsLegacy {
+		r.opts.Layout.Apply(event)
+	}
+	if r.opts.SuperAsCtrl && (event.ControlKeyState&SuperPressed) != 0 {
+		event.ControlKeyState &^= SuperPressed
+		event.ControlKeyState |= LeftCtrlPressed
+	}
+	if r.opts.DetectClicks && event.Type == MouseEventType {
+		r.detectClicks(event, time.Now())
+	}
+	if r.opts.TrackMouseButtons && event.Type == MouseEventType {
+		r.trackMouseButtons(event)
+	}
+}
+
+// detectClicks numbers a button press within its series of clicks. It must
+// run before trackMouseButtons, while ButtonState still names the button.
+func (r *Reader) detectClicks(event *InputEvent, now time.Time) {
+	if !event.KeyDown || event.ButtonState == 0 ||
+		(event.MouseEventFlags&(MouseMoved|MouseWheeled|MouseHWheeled)) != 0 {
+		return
+	}
+	x, y := int(event.MouseX), int(event.MouseY)
+	if r.opts.MousePixels {
+		x, y = int(event.MousePixelX), int(event.MousePixelY)
+	}
+	if event.ButtonState == r.clickButton && now.Sub(r.clickTime) <= r.opts.ClickInterval &&
+		abs(x-r.clickX) <= r.opts.ClickDistance && abs(y-r.clickY) <= r.opts.ClickDistance {
+		r.clickCount++
+	} else {
+		r.clickCount = 1
+	}
+	r.clickButton, r.clickTime, r.clickX, r.clickY = event.ButtonState, now, x, y
+
+	event.ClickCount = r.clickCount
+	if r.clickCount > 1 {
+		event.MouseEventFlags |= DoubleClick
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// trackMouseButtons updates the set of held buttons from a mouse report and
+// stores it in event.ButtonState.
+func (r *Reader) trackMouseButtons(event *InputEvent) {
+	switch {
+	case (event.MouseEventFlags & (MouseWheeled | MouseHWheeled)) != 0:
+		// Wheel reports carry no button state.
+	case (event.MouseEventFlags & MouseMoved) != 0:
+		// Drags report a single held button, plain motion none at all.
+		if event.ButtonState == 0 {
+			r.mouseButtons = 0
+		} else {
+			r.mouseButtons |= event.ButtonState
+		}
+	case event.KeyDown:
+		r.mouseButtons |= event.ButtonState
+	case event.ButtonState == 0:
+		// X10 and urxvt releases do not say which button went up.
+		r.mouseButtons = 0
+	default:
+		r.mouseButtons &^= event.ButtonState
+	}
+	event.ButtonState = r.mouseButtons
+}
+
+// readEvent parses the next event from the input stream. It returns a nil
+// event without error when a terminal reply completes a running Probe.
+func (r *Reader) readEvent(ctx context.Context) (*InputEvent, error) {
 	for {
 		select {
 		case <-r.done:
 			return nil, io.EOF
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		default:
 		}
-		if len(r.buf) > 0 {
+		if r.inPaste {
+			if event := r.readPaste(); event != nil {
+				return event, nil
+			}
+		} else if len(r.buf) == 0 && r.readErr != nil {
+			return nil, r.readErr
+		} else if len(r.buf) > 0 {
+			if r.opts.C1Controls && (r.buf[0] == c1CSI || r.buf[0] == c1SS3) {
+				r.expandC1()
+			}
 			// Optimization: Only attempt to parse sequences if the buffer starts with ESC.
 			if r.buf[0] == 0x1B {
+				c1 := r.c1Intro
+				r.c1Intro = false
+
 				// 1. Handle SS3 sequences (ESC O ...)
-				if event, consumed, err := ParseLegacySS3(r.buf); err == nil {
+				event, consumed, err := ParseLegacySS3(r.buf)
+				if err == ErrInvalidSequence && r.buf[1] == 'O' {
+					event, consumed, err = ParseRxvt(r.buf)
+				}
+				if err == nil {
 					r.buf = r.buf[consumed:]
 					return event, nil
 				} else if err == ErrIncomplete {
@@ -48,25 +464,78 @@ func (r *Reader) ReadEvent() (*InputEvent, error) {
 				}
 
 				// 2. Handle CSI sequences (ESC [ ...)
-				if terminatorIdx, command, err := scanCSI(r.buf); err == nil {
+				if terminatorIdx, command, err := scanCSIOrRxvt(r.buf); err == nil {
 					var event *InputEvent
 					var consumed int
 					var pErr error
 
-					if command == 'I' && terminatorIdx == 2 {
+					if reply, ok := parseTerminalReply(r.buf[:terminatorIdx+1]); ok {
+						// Answers to our own queries are not input; keep them out of the event stream.
+						r.buf = r.buf[terminatorIdx+1:]
+						if reply.command == 't' {
+							r.cellSize.Store(uint64(uint32(reply.params[2]))<<32 | uint64(uint32(reply.params[1])))
+						}
+						if r.probe != nil && r.probe.record(reply) {
+							return nil, nil
+						}
+						continue
+					} else if command == 'I' && terminatorIdx == 2 {
 						event, consumed = &InputEvent{Type: FocusEventType, SetFocus: true}, 3
 					} else if command == 'O' && terminatorIdx == 2 {
 						event, consumed = &InputEvent{Type: FocusEventType, SetFocus: false}, 3
 					} else if command == '~' && string(r.buf[2:terminatorIdx]) == "200" {
+						if r.opts.CollectPaste {
+							r.buf = r.buf[terminatorIdx+1:]
+							r.inPaste = true
+							r.paste = r.paste[:0]
+							continue
+						}
 						event, consumed = &InputEvent{Type: PasteEventType, PasteStart: true}, terminatorIdx+1
 					} else if command == '~' && string(r.buf[2:terminatorIdx]) == "201" {
 						event, consumed = &InputEvent{Type: PasteEventType, PasteStart: false}, terminatorIdx+1
 					} else {
 						switch command {
+						case 'R': // Cursor Position Report or legacy F3
+							if r.isCursorReport(r.buf[:terminatorIdx+1]) {
+								event, consumed, pErr = ParseCursorPosition(r.buf)
+								if pErr == nil && r.cprPending.Load() > 0 {
+									r.cprPending.Add(-1)
+								}
+							} else {
+								event, consumed, pErr = ParseKitty(r.buf)
+								if pErr == ErrInvalidSequence {
+									event, consumed, pErr = ParseLegacyCSI(r.buf)
+								}
+							}
 						case '_': // Win32 Input Mode
 							event, consumed, pErr = ParseWin32InputEvent(r.buf)
-						case 'M', 'm': // SGR Mouse
-							event, consumed, pErr = ParseMouseSGR(r.buf)
+						case 'M', 'm':
+							switch {
+							case command == 'M' && terminatorIdx == 2: // X10 / UTF-8 (1005) Mouse
+								event, consumed, pErr = ParseMouseX10(r.buf, r.opts.MouseUTF8)
+							case r.buf[2] == '<' && r.opts.MousePixels: // SGR-Pixels Mouse
+								event, consumed, pErr = ParseMouseSGRPixels(r.buf)
+								if pErr == nil {
+									cellWidth, cellHeight := r.CellSize()
+									col, row := PixelsToCells(int(event.MousePixelX), int(event.MousePixelY), cellWidth, cellHeight)
+									event.MouseX, event.MouseY = uint16(col), uint16(row)
+								}
+							case r.buf[2] == '<': // SGR Mouse
+								event, consumed, pErr = ParseMouseSGR(r.buf)
+							default: // urxvt (1015) Mouse
+								event, consumed, pErr = ParseMouseURXVT(r.buf)
+							}
+						case '~':
+							if bytes.HasPrefix(r.buf[2:terminatorIdx], []byte("27;")) { // xterm modifyOtherKeys
+								event, consumed, pErr = ParseModifyOtherKeys(r.buf)
+								break
+							}
+							event, consumed, pErr = ParseKitty(r.buf)
+							if pErr == ErrInvalidSequence {
+								event, consumed, pErr = ParseLegacyCSI(r.buf)
+							}
+						case '$', '^', '@', 'a', 'b', 'c', 'd': // rxvt modified keys
+							event, consumed, pErr = ParseRxvt(r.buf)
 						default: // Kitty Protocol or Legacy CSI
 							event, consumed, pErr = ParseKitty(r.buf)
 							if pErr == ErrInvalidSequence {
@@ -78,11 +547,20 @@ func (r *Reader) ReadEvent() (*InputEvent, error) {
 					if pErr == nil && event != nil {
 						r.buf = r.buf[consumed:]
 						return event, nil
+					} else if pErr == ErrIncomplete {
+						goto waitForMore
 					}
 				} else if err == ErrIncomplete {
 					goto waitForMore
 				}
 
+				// An 8-bit introducer that starts nothing known is dropped; it
+				// is not a key and must not turn into Alt+[ or Alt+O.
+				if c1 {
+					r.buf = r.buf[2:]
+					continue
+				}
+
 				// 3. Handle Double ESC
 				if len(r.buf) >= 2 && r.buf[1] == 0x1B {
 					r.buf = r.buf[2:]
@@ -94,6 +572,14 @@ func (r *Reader) ReadEvent() (*InputEvent, error) {
 					r.buf = r.buf[1:]
 					character, size := utf8.DecodeRune(r.buf)
 					r.buf = r.buf[size:]
+					// Alt with a control key: decode the key as if ESC were absent.
+					if character == 0x7F {
+						return &InputEvent{Type: KeyEventType, VirtualKeyCode: VK_BACK, ControlKeyState: LeftAltPressed, KeyDown: true, IsLegacy: true}, nil
+					}
+					if event := translateLegacyByte(character); event != nil {
+						event.ControlKeyState |= LeftAltPressed
+						return event, nil
+					}
 					return &InputEvent{
 						Type:            KeyEventType,
 						Char:            character,
@@ -104,16 +590,39 @@ func (r *Reader) ReadEvent() (*InputEvent, error) {
 				}
 
 			waitForMore:
+				r.c1Intro = c1
+				if r.readErr != nil {
+					// Nothing more is coming, so the sequence can never complete.
+					if c1 {
+						r.c1Intro = false
+						r.buf = r.buf[2:]
+						continue
+					}
+					r.buf = r.buf[1:]
+					return &InputEvent{Type: KeyEventType, VirtualKeyCode: VK_ESCAPE, KeyDown: true}, nil
+				}
+				waitStart := time.Now()
 				select {
-				case b := <-r.dataChan:
-					r.buf = append(r.buf, b)
+				case chunk := <-r.dataChan:
+					r.appendChunk(chunk)
+					r.observeEscLatency(time.Since(waitStart))
 					continue
-				case <-time.After(100 * time.Millisecond):
+				case <-r.escTimer():
+					r.escExpired = waitStart
+					if c1 {
+						r.c1Intro = false
+						r.buf = r.buf[2:]
+						continue
+					}
 					r.buf = r.buf[1:]
 					return &InputEvent{Type: KeyEventType, VirtualKeyCode: VK_ESCAPE, KeyDown: true}, nil
 				case err := <-r.errChan:
-					if len(r.buf) == 0 { return nil, err }
+					r.setReadErr(err)
 					continue
+				case <-r.done:
+					return nil, io.EOF
+				case <-ctx.Done():
+					return nil, ctx.Err()
 				}
 			}
 
@@ -122,7 +631,7 @@ func (r *Reader) ReadEvent() (*InputEvent, error) {
 				return &InputEvent{Type: KeyEventType, VirtualKeyCode: VK_BACK, KeyDown: true, IsLegacy: true}, nil
 			}
 
-			if utf8.FullRune(r.buf) {
+			if utf8.FullRune(r.buf) || r.readErr != nil {
 				character, size := utf8.DecodeRune(r.buf)
 				consumed := size
 				r.buf = r.buf[consumed:]
@@ -134,15 +643,126 @@ func (r *Reader) ReadEvent() (*InputEvent, error) {
 		}
 
 		select {
-		case b := <-r.dataChan:
-			r.buf = append(r.buf, b)
+		case chunk := <-r.dataChan:
+			if !r.escExpired.IsZero() {
+				if elapsed := time.Since(r.escExpired); len(r.buf) == 0 && (chunk[0] == '[' || chunk[0] == 'O') && elapsed < r.opts.MaxEscTimeout {
+					r.observeEscLatency(elapsed)
+				}
+				r.escExpired = time.Time{}
+			}
+			r.appendChunk(chunk)
 		case err := <-r.errChan:
-			if len(r.buf) == 0 { return nil, err }
+			r.setReadErr(err)
+		case <-r.done:
+			return nil, io.EOF
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// expandC1 rewrites the 8-bit introducer at the start of the buffer into its
+// 7-bit form (ESC [ or ESC O), which is what the parsers understand. The
+// buffer is shifted in place, so a run of introducers does not reallocate.
+func (r *Reader) expandC1() {
+	introducer := byte('[')
+	if r.buf[0] == c1SS3 {
+		introducer = 'O'
+	}
+	r.buf = append(r.buf, 0)
+	copy(r.buf[2:], r.buf[1:])
+	r.buf[0], r.buf[1] = 0x1B, introducer
+	r.c1Intro = true
+}
+
+// setReadErr records the error that stopped the background goroutine.
+// The goroutine queues all data before reporting an error, so whatever is
+// still in dataChan is drained first to keep it from being lost.
+func (r *Reader) setReadErr(err error) {
+	for {
+		select {
+		case chunk := <-r.dataChan:
+			r.appendChunk(chunk)
+		default:
+			r.readErr = err
+			return
+		}
+	}
+}
+
+// sendChunk hands a copy of p to the parsing side. It is called by the
+// platform goroutine and reports false once the Reader has been closed.
+func (r *Reader) sendChunk(p []byte) bool {
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+	select {
+	case r.dataChan <- chunk:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// appendChunk adds a chunk received from the platform goroutine to the buffer.
+func (r *Reader) appendChunk(chunk []byte) {
+	// Far2l's terminal emulator sends '= ' as two separate legacy keydown
+	// events in a single write; drop the spurious space. Pasted text is kept
+	// verbatim, and so is a chunk that continues buffered input.
+	if !r.inPaste && len(r.buf) == 0 && len(chunk) == 2 && chunk[0] == '=' && chunk[1] == ' ' {
+		chunk = chunk[:1]
+	}
+	if len(r.buf) == 0 {
+		// Chunks are private copies, so an empty buffer can adopt one as is.
+		r.buf = chunk
+		return
+	}
+	r.buf = append(r.buf, chunk...)
+}
+
+// Events returns a channel that delivers parsed events, for use in a select
+// alongside timers, signals or other work. The first call starts a goroutine
+// that feeds the channel; it is closed once reading stops, after which Err
+// reports the cause. Events must not be mixed with direct ReadEvent calls.
+func (r *Reader) Events() <-chan *InputEvent {
+	r.eventsOnce.Do(func() {
+		r.events = make(chan *InputEvent)
+		go r.pumpEvents()
+	})
+	return r.events
+}
+
+// Err returns the error that closed the Events channel.
+// It returns nil if the input simply ended (io.EOF) or the Reader was closed.
+func (r *Reader) Err() error {
+	r.eventsMu.Lock()
+	defer r.eventsMu.Unlock()
+	return r.eventsErr
+}
+
+func (r *Reader) pumpEvents() {
+	defer close(r.events)
+	for {
+		event, err := r.ReadEvent()
+		if err != nil {
+			if err != io.EOF {
+				r.eventsMu.Lock()
+				r.eventsErr = err
+				r.eventsMu.Unlock()
+			}
+			return
+		}
+		select {
+		case r.events <- event:
+		case <-r.done:
+			return
 		}
 	}
 }
 
 func translateLegacyByte(r rune) *InputEvent {
+	if r >= 0x20 {
+		return nil
+	}
 	evt := &InputEvent{Type: KeyEventType, KeyDown: true, IsLegacy: true}
 	switch r {
 	case 0x00:
@@ -0,0 +1,575 @@
+package vtinput
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestReadEventContext_Deadline(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	e, err := r.ReadEventContext(ctx)
+	if err != context.DeadlineExceeded || e != nil {
+		t.Fatalf("Expected DeadlineExceeded, got (%+v, %v)", e, err)
+	}
+
+	// The reader must stay usable after a cancelled read.
+	go pw.Write([]byte("x"))
+	e, err = r.ReadEvent()
+	if err != nil || e.Char != 'x' {
+		t.Errorf("Expected 'x' after cancelled read, got (%+v, %v)", e, err)
+	}
+}
+
+func TestReadEventContext_KeepsPartialSequence(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	// Half of Ctrl+Up arrives, then the caller gives up before the ESC timeout.
+	go pw.Write([]byte("\x1b[1;5"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := r.ReadEventContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	go pw.Write([]byte("A"))
+	e, err := r.ReadEvent()
+	if err != nil {
+		t.Fatalf("ReadEvent failed: %v", err)
+	}
+	if e.VirtualKeyCode != VK_UP || (e.ControlKeyState&LeftCtrlPressed) == 0 {
+		t.Errorf("Expected Ctrl+Up from resumed sequence, got %+v", e)
+	}
+}
+
+func TestEvents_Stream(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	go func() {
+		pw.Write([]byte("ab"))
+		pw.Close()
+	}()
+
+	var chars []rune
+	for e := range r.Events() {
+		chars = append(chars, e.Char)
+	}
+	if string(chars) != "ab" {
+		t.Errorf("Expected events for \"ab\", got %q", string(chars))
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Expected nil Err after EOF, got %v", err)
+	}
+}
+
+func TestEvents_CellSize(t *testing.T) {
+	// Run with -race: the reply is consumed by the Events goroutine while
+	// this one polls CellSize.
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	var query bytes.Buffer
+	if err := r.RequestCellSize(&query); err != nil || query.String() != "\x1b[16t" {
+		t.Fatalf("RequestCellSize wrote %q, err %v", query.String(), err)
+	}
+	go func() {
+		pw.Write([]byte("a"))
+		pw.Write([]byte("\x1b[6;16;8t"))
+		pw.Write([]byte("b"))
+		pw.Close()
+	}()
+
+	var chars []rune
+	for e := range r.Events() {
+		r.CellSize()
+		chars = append(chars, e.Char)
+	}
+	if string(chars) != "ab" {
+		t.Errorf("Expected events for \"ab\", got %q", string(chars))
+	}
+	if w, h := r.CellSize(); w != 8 || h != 16 {
+		t.Errorf("CellSize() = %d, %d", w, h)
+	}
+}
+
+func TestEvents_Error(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	pw.CloseWithError(io.ErrUnexpectedEOF)
+
+	select {
+	case _, ok := <-r.Events():
+		if ok {
+			t.Fatal("Expected closed channel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Events channel was not closed")
+	}
+	if err := r.Err(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestEvents_Close(t *testing.T) {
+	pr, _ := io.Pipe()
+	r := NewReader(pr)
+	events := r.Events()
+	r.Close()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("Expected closed channel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Events channel was not closed by Close")
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Expected nil Err after Close, got %v", err)
+	}
+}
+
+func TestReaderOptions_EscTimeout(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReaderWithOptions(pr, ReaderOptions{EscTimeout: 20 * time.Millisecond})
+	defer r.Close()
+
+	go pw.Write([]byte{0x1B})
+
+	start := time.Now()
+	e, err := r.ReadEvent()
+	if err != nil || e.VirtualKeyCode != VK_ESCAPE {
+		t.Fatalf("Expected VK_ESCAPE, got (%+v, %v)", e, err)
+	}
+	if d := time.Since(start); d > 90*time.Millisecond {
+		t.Errorf("Custom ESC timeout ignored, took %v", d)
+	}
+}
+
+func TestReaderOptions_EscNever(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReaderWithOptions(pr, ReaderOptions{EscPolicy: EscNever})
+	defer r.Close()
+
+	go pw.Write([]byte{0x1B})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if e, err := r.ReadEventContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected lone ESC to stay pending, got (%+v, %v)", e, err)
+	}
+
+	go pw.Write([]byte("[A"))
+	e, err := r.ReadEvent()
+	if err != nil || e.VirtualKeyCode != VK_UP {
+		t.Errorf("Expected VK_UP from late sequence tail, got (%+v, %v)", e, err)
+	}
+}
+
+func TestReaderOptions_EscAdaptive(t *testing.T) {
+	r := &Reader{opts: ReaderOptions{EscTimeout: 10 * time.Millisecond, MaxEscTimeout: 200 * time.Millisecond}}
+	r.escPolicy.Store(int32(EscAdaptive))
+
+	r.observeEscLatency(40 * time.Millisecond)
+	start := time.Now()
+	<-r.escTimer()
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("Adaptive timeout did not grow with latency, fired after %v", d)
+	}
+
+	r.observeEscLatency(time.Second)
+	r.observeEscLatency(time.Second)
+	start = time.Now()
+	<-r.escTimer()
+	if d := time.Since(start); d > 400*time.Millisecond {
+		t.Errorf("Adaptive timeout exceeded MaxEscTimeout, fired after %v", d)
+	}
+}
+
+func benchmarkReadAll(b *testing.B, opts ReaderOptions, input []byte) {
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := NewReaderWithOptions(bytes.NewReader(input), opts)
+		for {
+			if _, err := r.ReadEvent(); err != nil {
+				break
+			}
+		}
+		r.Close()
+	}
+}
+
+func largePaste() []byte {
+	text := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\r"), 1<<20/45)
+	input := append([]byte("\x1b[200~"), text...)
+	return append(input, "\x1b[201~"...)
+}
+
+func BenchmarkReadEvent_LargePaste(b *testing.B) {
+	benchmarkReadAll(b, ReaderOptions{}, largePaste())
+}
+
+func BenchmarkReadEvent_CollectPaste(b *testing.B) {
+	benchmarkReadAll(b, ReaderOptions{CollectPaste: true}, largePaste())
+}
+
+func BenchmarkReadEvent_MouseFlood(b *testing.B) {
+	var input []byte
+	for i := 0; i < 20000; i++ {
+		input = fmt.Appendf(input, "\x1b[<35;%d;%dM", i%200+1, i%50+1)
+	}
+	benchmarkReadAll(b, ReaderOptions{}, input)
+}
+
+func readPasteEvents(t *testing.T, opts ReaderOptions, chunks ...string) []*InputEvent {
+	t.Helper()
+	pr, pw := io.Pipe()
+	r := NewReaderWithOptions(pr, opts)
+	defer r.Close()
+
+	go func() {
+		for _, c := range chunks {
+			pw.Write([]byte(c))
+		}
+		pw.Close()
+	}()
+
+	var events []*InputEvent
+	for {
+		e, err := r.ReadEvent()
+		if err != nil {
+			return events
+		}
+		events = append(events, e)
+	}
+}
+
+func TestReadEvent_CollectPaste(t *testing.T) {
+	events := readPasteEvents(t, ReaderOptions{CollectPaste: true},
+		"\x1b[200~one\x1b\r\x01", "two\x1b[2", "01~x")
+
+	if len(events) != 2 {
+		t.Fatalf("Expected paste + key, got %v", events)
+	}
+	e := events[0]
+	if e.Type != PasteEventType || e.PasteText != "one\x1b\r\x01two" || !e.PasteCollected || e.PastePartial || e.PasteTruncated {
+		t.Errorf("Expected verbatim paste text, got %+v", e)
+	}
+	if events[1].Char != 'x' {
+		t.Errorf("Expected 'x' after paste, got %+v", events[1])
+	}
+}
+
+func TestReadEvent_CollectPasteMaxSize(t *testing.T) {
+	events := readPasteEvents(t, ReaderOptions{CollectPaste: true, MaxPasteSize: 5},
+		"\x1b[200~ab", "вгд\x1b[201~")
+
+	if len(events) != 1 || events[0].PasteText != "abв" || !events[0].PasteTruncated {
+		t.Errorf("Expected paste truncated at a character boundary, got %v", events)
+	}
+}
+
+func TestReadEvent_CollectPasteEmpty(t *testing.T) {
+	events := readPasteEvents(t, ReaderOptions{CollectPaste: true}, "\x1b[200~\x1b[201~")
+
+	if len(events) != 1 || !events[0].PasteCollected || events[0].PasteText != "" || events[0].PasteStart {
+		t.Fatalf("Expected one empty collected paste, got %v", events)
+	}
+	if s := events[0].String(); s != "Paste{TEXT:0 bytes}" {
+		t.Errorf("String() = %q, want it distinct from an end marker", s)
+	}
+}
+
+func TestReadEvent_StreamPaste(t *testing.T) {
+	events := readPasteEvents(t, ReaderOptions{CollectPaste: true, StreamPaste: true, MaxPasteSize: 4},
+		"\x1b[200~abcdefghij\x1b[201~")
+
+	var got []string
+	for _, e := range events {
+		if e.Type != PasteEventType {
+			t.Fatalf("Unexpected event %+v", e)
+		}
+		got = append(got, fmt.Sprintf("%s/%v", e.PasteText, e.PastePartial))
+	}
+	want := "abcd/true efgh/true ij/false"
+	if fmt.Sprint(got) != "["+want+"]" {
+		t.Errorf("got %v, want [%s]", got, want)
+	}
+}
+
+func TestReadEvent_StreamPasteSplitRune(t *testing.T) {
+	// The second byte of 'я' arrives in a later read.
+	events := readPasteEvents(t, ReaderOptions{CollectPaste: true, StreamPaste: true},
+		"\x1b[200~a\xd1", "\x8f\x1b[201~")
+
+	var text string
+	for _, e := range events {
+		if !utf8.ValidString(e.PasteText) {
+			t.Errorf("Paste event split a character: %q", e.PasteText)
+		}
+		text += e.PasteText
+	}
+	if text != "aя" || events[len(events)-1].PastePartial {
+		t.Errorf("Expected complete stream \"aя\", got %v", events)
+	}
+}
+
+func TestReadEvent_CursorPosition(t *testing.T) {
+	// Without an outstanding query CSI 1;5R is Ctrl+F3, while a report for
+	// any other row can only be a CPR.
+	r := NewReader(bytes.NewReader([]byte("\x1b[1;5R\x1b[7;3R")))
+	e, err := r.ReadEvent()
+	if err != nil || e.Type != KeyEventType || e.VirtualKeyCode != VK_F3 || (e.ControlKeyState&LeftCtrlPressed) == 0 {
+		t.Errorf("Expected Ctrl+F3, got (%+v, %v)", e, err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.Type != CursorPositionEventType || e.CursorRow != 6 || e.CursorCol != 2 {
+		t.Errorf("Expected CPR 7;3, got (%+v, %v)", e, err)
+	}
+
+	// With a query outstanding, the ambiguous form is a report, once.
+	r = NewReader(bytes.NewReader([]byte("\x1b[1;5R\x1b[1;5R")))
+	var query bytes.Buffer
+	if err := r.RequestCursorPosition(&query); err != nil || query.String() != "\x1b[6n" {
+		t.Fatalf("RequestCursorPosition wrote %q, err %v", query.String(), err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.Type != CursorPositionEventType || e.CursorRow != 0 || e.CursorCol != 4 {
+		t.Errorf("Expected CPR 1;5, got (%+v, %v)", e, err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.Type != KeyEventType || e.VirtualKeyCode != VK_F3 {
+		t.Errorf("Expected Ctrl+F3 after the report was consumed, got (%+v, %v)", e, err)
+	}
+}
+
+func TestReaderOptions_SuperAsCtrl(t *testing.T) {
+	input := []byte("\x1b[99;9u")
+
+	e, err := NewReader(bytes.NewReader(input)).ReadEvent()
+	if err != nil || e.ControlKeyState != SuperPressed {
+		t.Errorf("Expected Super+c by default, got (%+v, %v)", e, err)
+	}
+
+	e, err = NewReaderWithOptions(bytes.NewReader(input), ReaderOptions{SuperAsCtrl: true}).ReadEvent()
+	if err != nil || e.VirtualKeyCode != VK_C || e.ControlKeyState != LeftCtrlPressed {
+		t.Errorf("Expected Super folded into Ctrl+c, got (%+v, %v)", e, err)
+	}
+}
+
+func TestReadEvent_MouseX10(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+	defer r.Close()
+
+	// The report is split right after CSI M, then followed by a key.
+	go func() {
+		pw.Write([]byte("\x1b[M"))
+		pw.Write([]byte{32, 32 + 5, 32 + 7, 'a'})
+	}()
+
+	e, err := r.ReadEvent()
+	if err != nil || e.Type != MouseEventType || e.MouseX != 4 || e.MouseY != 6 || e.ButtonState != FromLeft1stButtonPressed {
+		t.Fatalf("Expected X10 mouse press, got (%+v, %v)", e, err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.Char != 'a' {
+		t.Errorf("Expected 'a' after mouse report, got (%+v, %v)", e, err)
+	}
+}
+
+func TestReadEvent_MousePixels(t *testing.T) {
+	// The cell size reply is consumed silently and used for the report after it.
+	input := []byte("\x1b[<0;1;1M\x1b[6;16;8t\x1b[<0;45;40M")
+	r := NewReaderWithOptions(bytes.NewReader(input), ReaderOptions{MousePixels: true})
+
+	e, err := r.ReadEvent()
+	if err != nil || e.MousePixelX != 0 || e.MousePixelY != 0 || e.MouseX != 0 || e.MouseY != 0 {
+		t.Fatalf("Expected pixel report at origin, got (%+v, %v)", e, err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.MousePixelX != 44 || e.MousePixelY != 39 || e.MouseX != 5 || e.MouseY != 2 {
+		t.Fatalf("Expected pixel report in cell (5, 2), got (%+v, %v)", e, err)
+	}
+	if w, h := r.CellSize(); w != 8 || h != 16 {
+		t.Errorf("CellSize() = %d, %d", w, h)
+	}
+}
+
+func TestReaderOptions_TrackMouseButtons(t *testing.T) {
+	// Left down, right down, drag, left up, right up, plain motion.
+	input := "\x1b[<0;1;1M\x1b[<2;1;1M\x1b[<32;2;1M\x1b[<0;2;1m\x1b[<2;2;1m\x1b[<35;3;1M"
+	want := []uint32{
+		FromLeft1stButtonPressed,
+		FromLeft1stButtonPressed | RightmostButtonPressed,
+		FromLeft1stButtonPressed | RightmostButtonPressed,
+		RightmostButtonPressed,
+		0,
+		0,
+	}
+	r := NewReaderWithOptions(bytes.NewReader([]byte(input)), ReaderOptions{TrackMouseButtons: true})
+	for i, buttons := range want {
+		e, err := r.ReadEvent()
+		if err != nil || e.Type != MouseEventType || e.ButtonState != buttons {
+			t.Errorf("Event %d: expected ButtonState %#x, got (%+v, %v)", i, buttons, e, err)
+		}
+	}
+}
+
+func TestReaderOptions_DetectClicks(t *testing.T) {
+	r := &Reader{opts: ReaderOptions{DetectClicks: true, ClickInterval: 300 * time.Millisecond, ClickDistance: 1}}
+	press := func(x, y uint16, button uint32) *InputEvent {
+		return &InputEvent{Type: MouseEventType, MouseX: x, MouseY: y, ButtonState: button, KeyDown: true}
+	}
+	start := time.Now()
+	steps := []struct {
+		event *InputEvent
+		at    time.Duration
+		count uint16
+	}{
+		{press(5, 5, FromLeft1stButtonPressed), 0, 1},
+		{&InputEvent{Type: MouseEventType, MouseX: 5, MouseY: 5, ButtonState: FromLeft1stButtonPressed}, 50 * time.Millisecond, 0},
+		{press(6, 5, FromLeft1stButtonPressed), 100 * time.Millisecond, 2},
+		{press(6, 4, FromLeft1stButtonPressed), 200 * time.Millisecond, 3},
+		{press(6, 4, FromLeft1stButtonPressed), 600 * time.Millisecond, 1}, // too late
+		{press(9, 4, FromLeft1stButtonPressed), 700 * time.Millisecond, 1}, // too far
+		{press(9, 4, RightmostButtonPressed), 800 * time.Millisecond, 1},   // other button
+	}
+	for i, step := range steps {
+		r.detectClicks(step.event, start.Add(step.at))
+		double := (step.event.MouseEventFlags & DoubleClick) != 0
+		if step.event.ClickCount != step.count || double != (step.count > 1) {
+			t.Errorf("Step %d: expected %d clicks, got %+v", i, step.count, step.event)
+		}
+	}
+
+	// With MousePixels the cell position may still be unknown (0, 0), so
+	// presses are compared in pixels.
+	r = &Reader{opts: ReaderOptions{DetectClicks: true, MousePixels: true, ClickDistance: 3}}
+	for i, step := range []struct {
+		x, y  uint16
+		count uint16
+	}{{100, 40, 1}, {102, 41, 2}, {300, 41, 1}} {
+		e := &InputEvent{Type: MouseEventType, MousePixelX: step.x, MousePixelY: step.y, ButtonState: FromLeft1stButtonPressed, KeyDown: true}
+		r.detectClicks(e, start)
+		if e.ClickCount != step.count {
+			t.Errorf("Pixel step %d: expected %d clicks, got %+v", i, step.count, e)
+		}
+	}
+}
+
+func TestReaderOptions_SynthesizeKeyUp(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReaderWithOptions(pr, ReaderOptions{SynthesizeKeyUp: true, KeyUpTimeout: 50 * time.Millisecond})
+	defer r.Close()
+
+	go pw.Write([]byte("aab"))
+
+	want := []struct {
+		char      rune
+		down      bool
+		synthetic bool
+	}{
+		{'a', true, false},
+		{'a', true, false}, // auto-repeat keeps the key down
+		{'a', false, true}, // released by the next key
+		{'b', true, false},
+		{'b', false, true}, // released by the timeout
+	}
+	for i, w := range want {
+		e, err := r.ReadEvent()
+		if err != nil || e.Char != w.char || e.KeyDown != w.down || e.IsSynthetic != w.synthetic {
+			t.Fatalf("Event %d: expected %q down=%v synthetic=%v, got (%+v, %v)", i, w.char, w.down, w.synthetic, e, err)
+		}
+	}
+}
+
+func TestReaderOptions_C1Controls(t *testing.T) {
+	input := "\x9bA\x8fP\x9b1;5Cț\x9b200~ab\x9b201~x"
+	r := NewReaderWithOptions(bytes.NewReader([]byte(input)), ReaderOptions{C1Controls: true, CollectPaste: true})
+
+	for _, vk := range []uint16{VK_UP, VK_F1, VK_RIGHT} {
+		e, err := r.ReadEvent()
+		if err != nil || e.VirtualKeyCode != vk {
+			t.Fatalf("Expected VK 0x%X, got (%+v, %v)", vk, e, err)
+		}
+	}
+	// 'ț' is C8 9B in UTF-8 and must not be mistaken for a CSI.
+	e, err := r.ReadEvent()
+	if err != nil || e.Char != 'ț' {
+		t.Fatalf("Expected 'ț', got (%+v, %v)", e, err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.Type != PasteEventType || e.PasteText != "ab" {
+		t.Fatalf("Expected paste \"ab\", got (%+v, %v)", e, err)
+	}
+	e, err = r.ReadEvent()
+	if err != nil || e.Char != 'x' {
+		t.Errorf("Expected 'x' after paste, got (%+v, %v)", e, err)
+	}
+
+	// 'Û' is C3 9B, so "Û201~" inside a paste is text, not an end marker,
+	// even when the character is split across reads.
+	for _, chunks := range [][]string{
+		{"\x1b[200~Û201~\x1b[201~"},
+		{"\x1b[200~\xc3", "\x9b201~\x1b[201~"},
+	} {
+		events := readPasteEvents(t, ReaderOptions{C1Controls: true, CollectPaste: true}, chunks...)
+		if len(events) != 1 || events[0].PasteText != "Û201~" {
+			t.Errorf("%q: expected paste \"Û201~\", got %v", chunks, events)
+		}
+	}
+
+	// A stray introducer is dropped rather than read as Alt+[.
+	r = NewReaderWithOptions(bytes.NewReader([]byte("\x9b\x9b\x9bz")), ReaderOptions{C1Controls: true})
+	e, err = r.ReadEvent()
+	if err != nil || e.Char != 'z' || e.ControlKeyState != 0 {
+		t.Errorf("Expected plain 'z' after stray CSIs, got (%+v, %v)", e, err)
+	}
+	if e, err = r.ReadEvent(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got (%+v, %v)", e, err)
+	}
+
+	// Without the option, a stray 0x9B is not an introducer.
+	e, err = NewReader(bytes.NewReader([]byte("\x9bA"))).ReadEvent()
+	if err != nil || e.VirtualKeyCode == VK_UP {
+		t.Errorf("Expected 0x9B to be ignored as CSI by default, got (%+v, %v)", e, err)
+	}
+}
+
+func TestReadEvent_CollectPasteKeepsEqualsSpace(t *testing.T) {
+	events := readPasteEvents(t, ReaderOptions{CollectPaste: true}, "\x1b[200~", "= ", "\x1b[201~")
+	if len(events) != 1 || events[0].PasteText != "= " {
+		t.Errorf("Expected paste \"= \" intact, got %v", events)
+	}
+}
+
+func TestReadEvent_AltControlByte(t *testing.T) {
+	e, err := NewReader(bytes.NewReader([]byte("\x1b\x13"))).ReadEvent()
+	if err != nil {
+		t.Fatalf("ReadEvent failed: %v", err)
+	}
+	chord, _ := ParseKey("Ctrl+Alt+S")
+	if e.VirtualKeyCode != VK_S || !chord.Match(e) {
+		t.Errorf("Expected Ctrl+Alt+S, got %+v", e)
+	}
+}
@@ -8,17 +8,11 @@ import (
 	"syscall"
 )
 
-func NewReader(in io.Reader) *Reader {
-	r := &Reader{
-		in:       in,
-		buf:      make([]byte, 0, 128),
-		dataChan: make(chan byte, 1024),
-		errChan:  make(chan error, 1),
-		done:     make(chan struct{}),
-	}
-
+// start launches the background goroutine feeding dataChan with read chunks.
+func (r *Reader) start() {
+	in := r.in
 	if err := syscall.Pipe(r.stopPipe[:]); err != nil {
-		return r
+		return
 	}
 
 	var fd int
@@ -30,7 +24,7 @@ func NewReader(in io.Reader) *Reader {
 
 	go func() {
 		defer syscall.Close(r.stopPipe[0])
-		tmp := make([]byte, 1024)
+		tmp := make([]byte, readChunkSize)
 
 		for {
 			if isAFile {
@@ -52,7 +46,7 @@ func NewReader(in io.Reader) *Reader {
 
 				n, err := syscall.Read(fd, tmp)
 				if n > 0 {
-					for i := 0; i < n; i++ { r.dataChan <- tmp[i] }
+					if !r.sendChunk(tmp[:n]) { return }
 				}
 				if err != nil {
 					if err == syscall.EAGAIN || err == syscall.EINTR { continue }
@@ -71,7 +65,7 @@ func NewReader(in io.Reader) *Reader {
 				default:
 					n, err := in.Read(tmp)
 					if n > 0 {
-						for i := 0; i < n; i++ { r.dataChan <- tmp[i] }
+						if !r.sendChunk(tmp[:n]) { return }
 					}
 					if err != nil {
 						r.errChan <- err
@@ -81,8 +75,6 @@ func NewReader(in io.Reader) *Reader {
 			}
 		}
 	}()
-
-	return r
 }
 
 func maxInt(a, b int) int {
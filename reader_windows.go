@@ -2,24 +2,12 @@
 
 package vtinput
 
-import (
-	"io"
-)
-
-// NewReader creates a new Reader instance for Windows.
+// start launches the background goroutine feeding dataChan with read chunks.
 // Since Windows resurrect/daemon logic is not yet implemented using
 // terminal FD passing, we use a simpler reading loop.
-func NewReader(in io.Reader) *Reader {
-	r := &Reader{
-		in:       in,
-		buf:      make([]byte, 0, 128),
-		dataChan: make(chan byte, 1024),
-		errChan:  make(chan error, 1),
-		done:     make(chan struct{}),
-	}
-
+func (r *Reader) start() {
 	go func() {
-		tmp := make([]byte, 1024)
+		tmp := make([]byte, readChunkSize)
 		for {
 			select {
 			case <-r.done:
@@ -27,8 +15,8 @@ func NewReader(in io.Reader) *Reader {
 			default:
 				n, err := r.in.Read(tmp)
 				if n > 0 {
-					for i := 0; i < n; i++ {
-						r.dataChan <- tmp[i]
+					if !r.sendChunk(tmp[:n]) {
+						return
 					}
 				}
 				if err != nil {
@@ -38,8 +26,6 @@ func NewReader(in io.Reader) *Reader {
 			}
 		}
 	}()
-
-	return r
 }
 
 func (r *Reader) platformClose() {
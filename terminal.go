@@ -11,16 +11,37 @@ const (
 	seqEnableWin32  = "\x1b[?9001h"
 	seqDisableWin32 = "\x1b[?9001l"
 
-	seqEnableKitty  = "\x1b[>15u"
-	seqDisableKitty = "\x1b[<1u"
+	// Kitty progressive enhancement flags: 1 disambiguate, 2 event types,
+	// 4 alternate keys, 8 all keys as escapes, 16 associated text.
+	seqEnableKitty     = "\x1b[>15u"
+	seqEnableKittyText = "\x1b[>31u"
+	seqDisableKitty    = "\x1b[<1u"
+
+	// xterm modifyOtherKeys level 2: modified keys as CSI 27 ; mod ; code ~
+	seqEnableModifyOtherKeys  = "\x1b[>4;2m"
+	seqDisableModifyOtherKeys = "\x1b[>4m"
+
+	// DECKPAM / DECKPNM: application / numeric keypad
+	seqEnableKeypad  = "\x1b="
+	seqDisableKeypad = "\x1b>"
 
 	// 1003: Any event mouse (motion + buttons), 1006: SGR extended mode
 	seqEnableMouse  = "\x1b[?1003h\x1b[?1006h"
 	seqDisableMouse = "\x1b[?1006l\x1b[?1003l"
 
+	// 1016: SGR-Pixels, like 1006 but with pixel coordinates. Terminals that
+	// lack it fall back to X10 reports, which are still parsed as cells.
+	seqEnableMousePixels  = "\x1b[?1003h\x1b[?1016h"
+	seqDisableMousePixels = "\x1b[?1016l\x1b[?1003l"
+
 	// 1004: Focus tracking, 2004: Bracketed paste
 	seqEnableExt  = "\x1b[?1004h\x1b[?2004h"
 	seqDisableExt = "\x1b[?2004l\x1b[?1004l"
+
+	// DSR 6: the terminal answers with a Cursor Position Report (CSI row ; col R)
+	seqRequestCursorPosition = "\x1b[6n"
+	// XTWINOPS 16: the terminal answers with its cell size (CSI 6 ; height ; width t)
+	seqRequestCellSize = "\x1b[16t"
 )
 // Protocol flags to selectively enable features.
 type Protocol uint32
@@ -30,6 +51,20 @@ const (
 	KittyKeyboard
 	MouseSupport
 	FocusAndPaste
+	// KittyAssociatedText additionally asks kitty to report the text each key
+	// produces (InputEvent.Text). Only meaningful together with KittyKeyboard.
+	KittyAssociatedText
+	// MousePixels is a variant of MouseSupport that reports pointer positions
+	// in pixels (SGR-Pixels, 1016). Read with ReaderOptions.MousePixels set.
+	MousePixels
+	// ModifyOtherKeys enables xterm's modifyOtherKeys mode, which tells
+	// apart keys like Ctrl+Enter, Ctrl+Tab or Ctrl+1 on terminals without
+	// the kitty protocol.
+	ModifyOtherKeys
+	// KeypadApplicationMode switches the numeric keypad to application mode
+	// (DECKPAM), so that legacy terminals report its keys as ESC O sequences
+	// distinct from the main keys.
+	KeypadApplicationMode
 
 	// DefaultProtocols enables all supported features.
 	DefaultProtocols = Win32InputMode | KittyKeyboard | MouseSupport | FocusAndPaste
@@ -55,14 +90,29 @@ func EnableProtocols(p Protocol) (func(), error) {
 	var enableSeq, disableSeq string
 
 	if p&KittyKeyboard != 0 {
-		enableSeq += seqEnableKitty
+		if p&KittyAssociatedText != 0 {
+			enableSeq += seqEnableKittyText
+		} else {
+			enableSeq += seqEnableKitty
+		}
 		disableSeq = seqDisableKitty + disableSeq // LIFO order for restore is good practice
 	}
+	if p&ModifyOtherKeys != 0 {
+		enableSeq += seqEnableModifyOtherKeys
+		disableSeq = seqDisableModifyOtherKeys + disableSeq
+	}
+	if p&KeypadApplicationMode != 0 {
+		enableSeq += seqEnableKeypad
+		disableSeq = seqDisableKeypad + disableSeq
+	}
 	if p&Win32InputMode != 0 {
 		enableSeq += seqEnableWin32
 		disableSeq = seqDisableWin32 + disableSeq
 	}
-	if p&MouseSupport != 0 {
+	if p&MousePixels != 0 {
+		enableSeq += seqEnableMousePixels
+		disableSeq = seqDisableMousePixels + disableSeq
+	} else if p&MouseSupport != 0 {
 		enableSeq += seqEnableMouse
 		disableSeq = seqDisableMouse + disableSeq
 	}